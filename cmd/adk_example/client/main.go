@@ -6,6 +6,7 @@ import (
 	"os"
 
 	mesh_adk "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/adk"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/telemetry"
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/cmd/launcher"
@@ -24,6 +25,12 @@ func main() {
 	}
 
 	ctx := context.Background()
+	shutdownTracing, err := telemetry.Setup(ctx, telemetry.ConfigFromEnv("root-agent"))
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+	defer shutdownTracing(ctx)
+
 	geminiModel, err := gemini.NewModel(ctx, "gemini-2.5-flash-lite", &genai.ClientConfig{
 		APIKey: os.Getenv("GOOGLE_API_KEY"),
 	})
@@ -4,8 +4,11 @@ import (
 	"context"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
 	mesh_adk "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/adk"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/telemetry"
 	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/model/gemini"
 	"google.golang.org/genai"
@@ -15,7 +18,14 @@ import (
 
 func main() {
 
-	ctx := context.Background()
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	shutdownTracing, err := telemetry.Setup(ctx, telemetry.ConfigFromEnv("summary-agent"))
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+	defer shutdownTracing(context.Background())
+
 	geminiModel, err := gemini.NewModel(ctx, "gemini-2.5-flash-lite", &genai.ClientConfig{
 		APIKey: os.Getenv("GOOGLE_API_KEY"),
 	})
@@ -34,9 +44,11 @@ func main() {
 	}
 
 	// 2. Start the AgentMesh Server
-	// This starts a gRPC server on port 50054 (or whatever your Sidecar is configured to talk to)
+	// This starts a gRPC server on port 50054 (or whatever your Sidecar is configured to talk to),
+	// with the agent's card on port 50055 for the Sidecar's --agent-card-url.
+	// It stops gracefully on SIGINT or SIGTERM.
 	log.Println("Starting Agent Server on port 50054...")
-	if err := mesh_adk.ServeAgent(50054, summaryAgent); err != nil {
+	if err := mesh_adk.ServeAgent(ctx, 50054, summaryAgent); err != nil {
 		log.Fatal(err)
 	}
 }
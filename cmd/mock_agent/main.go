@@ -1,71 +1,79 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"io"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
 	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+	registry "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/registry"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/mockagent"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
 )
 
-// MockAgentServer implements the A2AMeshServiceServer interface.
-type MockAgentServer struct {
-	mesh.UnimplementedA2AMeshServiceServer
+// echoScenario is played without --scenario: every task is acknowledged and
+// answered with its own text.
+var echoScenario = &mockagent.Scenario{
+	Name:        "mock-agent",
+	Description: "Echoes every message.",
+	Skills: map[string]mockagent.Script{
+		mockagent.DefaultSkill: {Steps: []mockagent.Step{
+			{Status: "WORKING", Message: "Mock Agent received your message!"},
+			{Artifact: &mockagent.Artifact{Name: "response", Text: "{input}"}},
+		}},
+	},
 }
 
-// StreamTask handles incoming tasks from the Sidecar.
-func (s *MockAgentServer) StreamTask(stream mesh.A2AMeshService_StreamTaskServer) error {
-	ctx := stream.Context()
-	md, _ := metadata.FromIncomingContext(ctx)
-	log.Printf("Mock Agent received connection. Metadata: %v", md)
+func main() {
+	port := flag.Int("port", 50054, "port to serve the mesh API on (the sidecar's app port)")
+	scenarioFile := flag.String("scenario", "", "YAML or JSON scenario to play (echoes messages when empty)")
+	cardFile := flag.String("agent-card", "", "JSON agent card to serve instead of the one generated from the scenario")
+	cardAddr := flag.String("card-addr", "localhost:50055", "address serving the card at /.well-known/agent-card.json for the sidecar's --agent-card-url (empty disables)")
+	flag.Parse()
 
-	for {
-		msg, err := stream.Recv()
-		if err == io.EOF {
-			return nil
-		}
+	scenario := echoScenario
+	if *scenarioFile != "" {
+		sc, err := mockagent.LoadScenario(*scenarioFile)
 		if err != nil {
-			log.Printf("Error receiving message: %v", err)
-			return err
-		}
-
-		log.Printf("Mock Agent received message: %v", msg)
-
-		// Echo back a response
-		response := &mesh.StreamEvent{
-			Event: &mesh.StreamEvent_StatusUpdate{
-				StatusUpdate: &mesh.TaskStatusUpdate{
-					TaskId:  "mock-task-id",
-					Status:  mesh.Task_WORKING,
-					Message: "Mock Agent received your message!",
-				},
-			},
+			log.Fatal(err)
 		}
+		scenario = sc
+	}
+	agent := mockagent.New(scenario)
 
-		if err := stream.Send(response); err != nil {
-			log.Printf("Error sending response: %v", err)
-			return err
-		}
+	card, err := loadCard(*cardFile, agent)
+	if err != nil {
+		log.Fatal(err)
 	}
-}
 
-func main() {
-	port := 50054
-	lis, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", port))
+	lis, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", *port))
 	if err != nil {
-		log.Fatalf("Failed to listen on port %d: %v", port, err)
+		log.Fatalf("Failed to listen on port %d: %v", *port, err)
 	}
 
 	grpcServer := grpc.NewServer()
-	mesh.RegisterA2AMeshServiceServer(grpcServer, &MockAgentServer{})
+	mesh.RegisterA2AMeshServiceServer(grpcServer, agent)
+
+	var cardServer *http.Server
+	if *cardAddr != "" {
+		cardServer = &http.Server{Addr: *cardAddr, Handler: cardHandler(card)}
+		go func() {
+			if err := cardServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("Failed to serve agent card: %v", err)
+			}
+		}()
+		log.Printf("Serving agent card on http://%s/.well-known/agent-card.json", *cardAddr)
+	}
 
-	log.Printf("Mock Agent listening on localhost:%d", port)
+	log.Printf("Mock Agent %q listening on localhost:%d", card.Name, *port)
 
 	// Graceful shutdown
 	go func() {
@@ -73,6 +81,9 @@ func main() {
 		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 		<-sigCh
 		log.Println("Shutting down Mock Agent...")
+		if cardServer != nil {
+			cardServer.Shutdown(context.Background())
+		}
 		grpcServer.GracefulStop()
 	}()
 
@@ -80,3 +91,33 @@ func main() {
 		log.Fatalf("Failed to serve: %v", err)
 	}
 }
+
+// loadCard reads the card in path, or generates it from the agent's scenario.
+func loadCard(path string, agent *mockagent.Agent) (*registry.AgentCard, error) {
+	if path == "" {
+		return agent.Card(), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agent card: %w", err)
+	}
+	card := &registry.AgentCard{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, card); err != nil {
+		return nil, fmt.Errorf("failed to parse agent card: %w", err)
+	}
+	return card, nil
+}
+
+// cardHandler serves card in its A2A JSON form.
+func cardHandler(card *registry.AgentCard) http.Handler {
+	data, err := protojson.Marshal(card)
+	if err != nil {
+		log.Fatalf("Failed to encode agent card: %v", err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/agent-card.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+	return mux
+}
@@ -1,23 +1,75 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"net"
+	nethttp "net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 
+	"github.com/ThisaraWeerakoon/Agent-Mesh/internal/adapters/auth"
 	grpcHandler "github.com/ThisaraWeerakoon/Agent-Mesh/internal/adapters/handler/grpc"
 	"github.com/ThisaraWeerakoon/Agent-Mesh/internal/adapters/handler/http"
 	"github.com/ThisaraWeerakoon/Agent-Mesh/internal/adapters/repository/memory"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/internal/config"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/internal/core/ports"
 	"github.com/ThisaraWeerakoon/Agent-Mesh/internal/core/services"
 	pb "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/registry"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/telemetry"
 )
 
 func main() {
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	if cfg.ValidateOnly {
+		log.Println("Configuration is valid")
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, cfg); err != nil {
+		log.Fatalf("Registry server error: %v", err)
+	}
+	log.Println("Registry server stopped")
+}
+
+// run starts the HTTP and gRPC servers and blocks until ctx is cancelled or one of them fails.
+func run(ctx context.Context, cfg *config.Config) error {
+	// 0. Initialize Tracing
+	shutdownTracing, err := telemetry.Setup(ctx, telemetry.Config{
+		ServiceName: "agent-registry",
+		Exporter:    cfg.TraceExporter,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer shutdownTracing(context.Background())
+
 	// 1. Initialize Adapters
-	repo := memory.NewRegistryRepository()
+	repo, err := newRepository(cfg.Storage)
+	if err != nil {
+		return err
+	}
 
 	// 2. Initialize Service
 	service := services.NewRegistryService(repo)
@@ -25,43 +77,158 @@ func main() {
 	// 3. Initialize Handlers
 	httpH := http.NewRegistryHandler(service)
 	grpcH := grpcHandler.NewRegistryServer(service)
+	healthH := health.NewServer()
+	authenticator := auth.NewAuthenticator(cfg.Auth)
 
 	// 4. Run Servers
-	go runGRPCServer(grpcH)
-	runHTTPServer(httpH)
-}
+	g, ctx := errgroup.WithContext(ctx)
 
-func runHTTPServer(handler *http.RegistryHandler) {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "3000"
+	g.Go(func() error {
+		grpcHandler.WatchHealth(ctx, service, healthH, cfg.HealthCheckInterval)
+		return nil
+	})
+
+	if cfg.HeartbeatTTL > 0 {
+		g.Go(func() error {
+			expireAgents(ctx, service, cfg.HeartbeatTTL)
+			return nil
+		})
 	}
 
-	r := http.SetupRouter(handler)
+	g.Go(func() error {
+		return runHTTPServer(ctx, cfg, httpH, authenticator)
+	})
+
+	g.Go(func() error {
+		return runGRPCServer(ctx, cfg, grpcH, healthH, authenticator)
+	})
+
+	return g.Wait()
+}
 
-	log.Printf("Starting A2A Registry HTTP Server on port %s", port)
-	if err := r.Run(":" + port); err != nil {
-		log.Fatalf("Failed to start HTTP server: %v", err)
+func newRepository(cfg config.StorageConfig) (ports.RegistryRepository, error) {
+	switch cfg.Backend {
+	case "memory":
+		return memory.NewRegistryRepository(), nil
+	default:
+		return nil, fmt.Errorf("unsupported storage backend: %q", cfg.Backend)
 	}
 }
 
-func runGRPCServer(handler *grpcHandler.RegistryServer) {
-	port := os.Getenv("GRPC_PORT")
-	if port == "" {
-		port = "50051"
+func runHTTPServer(ctx context.Context, cfg *config.Config, handler *http.RegistryHandler, authenticator *auth.Authenticator) error {
+	srv := &nethttp.Server{
+		Addr:    fmt.Sprintf(":%d", cfg.HTTP.Port),
+		Handler: http.SetupRouter(handler, http.AuthMiddleware(authenticator)),
 	}
+	if cfg.TLS.Enabled() {
+		tlsConfig, err := cfg.TLS.ServerConfig()
+		if err != nil {
+			return err
+		}
+		srv.TLSConfig = tlsConfig
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("Starting A2A Registry HTTP Server on port %d", cfg.HTTP.Port)
+		var err error
+		if cfg.TLS.Enabled() {
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
+		errCh <- err
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("http server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	log.Println("Draining HTTP server...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("HTTP drain deadline exceeded, forcing close: %v", err)
+		srv.Close()
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, nethttp.ErrServerClosed) {
+		return fmt.Errorf("http server failed: %w", err)
+	}
+	return nil
+}
 
-	lis, err := net.Listen("tcp", ":"+port)
+func runGRPCServer(ctx context.Context, cfg *config.Config, handler *grpcHandler.RegistryServer, healthH *health.Server, authenticator *auth.Authenticator) error {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPC.Port))
 	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+		return fmt.Errorf("failed to listen: %w", err)
 	}
 
-	s := grpc.NewServer()
+	opts := []grpc.ServerOption{
+		telemetry.ServerOption(),
+		grpc.UnaryInterceptor(grpcHandler.UnaryAuthInterceptor(authenticator)),
+		grpc.StreamInterceptor(grpcHandler.StreamAuthInterceptor(authenticator)),
+	}
+	if cfg.TLS.Enabled() {
+		tlsConfig, err := cfg.TLS.ServerConfig()
+		if err != nil {
+			return err
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	s := grpc.NewServer(opts...)
 	pb.RegisterRegistryServiceServer(s, handler)
+	healthpb.RegisterHealthServer(s, healthH)
 	reflection.Register(s) // Enable reflection for grpcurl
 
-	log.Printf("Starting A2A Registry gRPC Server on port %s", port)
-	if err := s.Serve(lis); err != nil {
-		log.Fatalf("failed to serve: %v", err)
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("Starting A2A Registry gRPC Server on port %d", cfg.GRPC.Port)
+		errCh <- s.Serve(lis)
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("grpc server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	log.Println("Draining gRPC server...")
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(cfg.ShutdownTimeout):
+		log.Println("gRPC drain deadline exceeded, forcing stop")
+		s.Stop()
+	}
+	return nil
+}
+
+// expireAgents periodically removes agents whose last heartbeat is older than ttl.
+func expireAgents(ctx context.Context, service ports.RegistryService, ttl time.Duration) {
+	ticker := time.NewTicker(ttl / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := service.ExpireAgents(ctx, time.Now().Add(-ttl))
+			if err != nil {
+				log.Printf("Failed to expire agents: %v", err)
+				continue
+			}
+			for _, agentID := range expired {
+				log.Printf("Expired agent %s (no heartbeat for %s)", agentID, ttl)
+			}
+		}
 	}
 }
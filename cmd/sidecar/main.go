@@ -1,29 +1,49 @@
 package main
+
 import (
 	"context"
+	"errors"
+	"flag"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+
+	"gopkg.in/yaml.v3"
+
 	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/sidecar"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/telemetry"
 )
+
 func main() {
-	cfg := sidecar.Config{
-		AgentID:      "agent-1",
-		RegistryURL:  "localhost:50051", // Adjust to Registry gRPC port
-		LocalPort:    50052,
-		ExternalPort: 50053,
-		AppPort:      50054, // Port where your mock agent runs
-		CertFile:     "certs/server-cert.pem",
-		KeyFile:      "certs/server-key.pem",
-		CAFile:       "certs/ca-cert.pem",
+	cfg, err := sidecar.LoadConfig(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if cfg.PrintConfig {
+		// Print even an invalid configuration so the merge result can be inspected.
+		out, _ := yaml.Marshal(cfg)
+		os.Stdout.Write(out)
+		if err != nil {
+			log.Fatalf("Invalid configuration: %v", err)
+		}
+		return
 	}
-	srv, err := sidecar.NewServer(cfg)
 	if err != nil {
-		log.Fatalf("Failed to create server: %v", err)
+		log.Fatalf("Invalid configuration: %v", err)
 	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
+	shutdownTracing, err := telemetry.Setup(ctx, telemetry.ConfigFromEnv("agentmesh-sidecar"))
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+	defer shutdownTracing(context.Background())
+	srv, err := sidecar.NewServer(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create server: %v", err)
+	}
 	// Handle SIGINT/SIGTERM
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
@@ -35,4 +55,4 @@ func main() {
 	if err := srv.Run(ctx); err != nil {
 		log.Fatalf("Sidecar error: %v", err)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"crypto/x509"
+	"errors"
+
+	"github.com/ThisaraWeerakoon/Agent-Mesh/internal/config"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/internal/core/domain"
+)
+
+// Authenticator maps verified client certificates to registry principals.
+type Authenticator struct {
+	enabled    bool
+	principals map[string]string
+	admins     map[string]bool
+}
+
+func NewAuthenticator(cfg config.AuthConfig) *Authenticator {
+	admins := make(map[string]bool, len(cfg.Admins))
+	for _, a := range cfg.Admins {
+		admins[a] = true
+	}
+	return &Authenticator{
+		enabled:    cfg.Mode == "mtls",
+		principals: cfg.Principals,
+		admins:     admins,
+	}
+}
+
+// Enabled reports whether callers must present a client certificate.
+func (a *Authenticator) Enabled() bool {
+	return a.enabled
+}
+
+// Authenticate resolves the principal for the leaf certificate of a verified chain.
+// The certificate's Common Name is looked up in the configured principal map and
+// used verbatim when no mapping exists.
+func (a *Authenticator) Authenticate(chains [][]*x509.Certificate) (domain.Principal, error) {
+	if len(chains) == 0 || len(chains[0]) == 0 {
+		return domain.Principal{}, errors.New("client certificate required")
+	}
+
+	identity := chains[0][0].Subject.CommonName
+	if identity == "" {
+		return domain.Principal{}, errors.New("client certificate has no common name")
+	}
+
+	name := identity
+	if mapped, ok := a.principals[identity]; ok {
+		name = mapped
+	}
+
+	return domain.Principal{Name: name, Admin: a.admins[name]}, nil
+}
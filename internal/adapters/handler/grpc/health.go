@@ -0,0 +1,19 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/health"
+
+	"github.com/ThisaraWeerakoon/Agent-Mesh/internal/core/ports"
+	pb "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/registry"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/meshconn"
+)
+
+// WatchHealth probes the registry service every interval and mirrors its readiness
+// into the grpc.health.v1 server, both for the whole server ("") and for RegistryService.
+// It blocks until ctx is cancelled, then marks the server NOT_SERVING.
+func WatchHealth(ctx context.Context, service ports.RegistryService, hs *health.Server, interval time.Duration) {
+	meshconn.WatchHealth(ctx, hs, pb.RegistryService_ServiceDesc.ServiceName, "Registry", interval, service.CheckReadiness)
+}
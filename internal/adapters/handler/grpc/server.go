@@ -29,14 +29,19 @@ func (s *RegistryServer) RegisterAgent(ctx context.Context, req *pb.RegisterAgen
 	tags := req.Tags
 	metadata := req.Metadata.AsMap()
 
-	// TODO: Get owner from auth context
 	owner := "anonymous"
+	if p, ok := domain.PrincipalFromContext(ctx); ok {
+		owner = p.Name
+	}
 
 	entry, err := s.service.RegisterAgent(ctx, agentCard, tags, metadata, owner)
 	if err != nil {
 		if err.Error() == "agent with this ID already exists" {
 			return nil, status.Error(codes.AlreadyExists, err.Error())
 		}
+		if err.Error() == "permission denied" {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
@@ -65,6 +70,9 @@ func (s *RegistryServer) UpdateAgent(ctx context.Context, req *pb.UpdateAgentReq
 		if err.Error() == "agent not found" {
 			return nil, status.Error(codes.NotFound, err.Error())
 		}
+		if err.Error() == "permission denied" {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
@@ -77,6 +85,9 @@ func (s *RegistryServer) DeleteAgent(ctx context.Context, req *pb.DeleteAgentReq
 		if err.Error() == "agent not found" {
 			return nil, status.Error(codes.NotFound, err.Error())
 		}
+		if err.Error() == "permission denied" {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
@@ -85,7 +96,7 @@ func (s *RegistryServer) DeleteAgent(ctx context.Context, req *pb.DeleteAgentReq
 
 func (s *RegistryServer) ListAgents(ctx context.Context, req *pb.ListAgentsRequest) (*pb.ListAgentsResponse, error) {
 	limit := int(req.Limit)
-	if limit == 0 {
+	if limit <= 0 {
 		limit = 50
 	}
 	offset := int(req.Offset)
@@ -125,6 +136,9 @@ func (s *RegistryServer) Heartbeat(ctx context.Context, req *pb.HeartbeatRequest
 		if err.Error() == "agent not found" {
 			return nil, status.Error(codes.NotFound, err.Error())
 		}
+		if err.Error() == "permission denied" {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
@@ -0,0 +1,35 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ThisaraWeerakoon/Agent-Mesh/internal/adapters/auth"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/internal/core/domain"
+)
+
+// AuthMiddleware attaches the principal derived from the client certificate to the request context.
+// It is a no-op when authentication is disabled.
+func AuthMiddleware(a *auth.Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !a.Enabled() {
+			c.Next()
+			return
+		}
+
+		if c.Request.TLS == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "client certificate required"})
+			return
+		}
+
+		p, err := a.Authenticate(c.Request.TLS.VerifiedChains)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Request = c.Request.WithContext(domain.WithPrincipal(c.Request.Context(), p))
+		c.Next()
+	}
+}
@@ -34,6 +34,9 @@ func (h *RegistryHandler) RegisterAgent(c *gin.Context) {
 	}
 
 	owner := "anonymous"
+	if p, ok := domain.PrincipalFromContext(c.Request.Context()); ok {
+		owner = p.Name
+	}
 
 	entry, err := h.service.RegisterAgent(c.Request.Context(), req.AgentCard, req.Tags, req.Metadata, owner)
 	if err != nil {
@@ -41,6 +44,10 @@ func (h *RegistryHandler) RegisterAgent(c *gin.Context) {
 			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 			return
 		}
+		if err.Error() == "permission denied" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -85,6 +92,10 @@ func (h *RegistryHandler) UpdateAgent(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
+		if err.Error() == "permission denied" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -102,6 +113,10 @@ func (h *RegistryHandler) DeleteAgent(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
+		if err.Error() == "permission denied" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -112,6 +127,9 @@ func (h *RegistryHandler) DeleteAgent(c *gin.Context) {
 // ListAgents handles GET /agents
 func (h *RegistryHandler) ListAgents(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit < 0 {
+		limit = 50
+	}
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 
 	filters := make(map[string]interface{})
@@ -149,6 +167,10 @@ func (h *RegistryHandler) Heartbeat(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
+		if err.Error() == "permission denied" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -158,3 +180,21 @@ func (h *RegistryHandler) Heartbeat(c *gin.Context) {
 		"lastHeartbeat": lastHeartbeat,
 	})
 }
+
+// Livez handles GET /livez
+func (h *RegistryHandler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "alive"})
+}
+
+// Readyz handles GET /readyz
+func (h *RegistryHandler) Readyz(c *gin.Context) {
+	if err := h.service.CheckReadiness(c.Request.Context()); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status": "not ready",
+			"error":  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ready"})
+}
@@ -4,10 +4,14 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-func SetupRouter(handler *RegistryHandler) *gin.Engine {
+// SetupRouter builds the registry router. Optional middleware (e.g. AuthMiddleware)
+// is applied to the agent API only, leaving the health endpoints open.
+func SetupRouter(handler *RegistryHandler, middleware ...gin.HandlerFunc) *gin.Engine {
 	r := gin.Default()
+	r.Use(otelgin.Middleware("agent-registry"))
 
 	// Health check
 	r.GET("/health", func(c *gin.Context) {
@@ -15,8 +19,10 @@ func SetupRouter(handler *RegistryHandler) *gin.Engine {
 			"status":   "healthy",
 		})
 	})
+	r.GET("/livez", handler.Livez)
+	r.GET("/readyz", handler.Readyz)
 
-	api := r.Group("/api/v1/agents")
+	api := r.Group("/api/v1/agents", middleware...)
 	{
 		api.POST("/", handler.RegisterAgent)
 		api.GET("/:agentId", handler.GetAgent)
@@ -15,11 +15,14 @@ import (
 type MemoryRegistryRepository struct {
 	mu    sync.RWMutex
 	store map[string]*domain.RegistryEntry
+	// owners binds each agent ID ever registered to its first owner.
+	owners map[string]string
 }
 
 func NewRegistryRepository() ports.RegistryRepository {
 	return &MemoryRegistryRepository{
-		store: make(map[string]*domain.RegistryEntry),
+		store:  make(map[string]*domain.RegistryEntry),
+		owners: make(map[string]string),
 	}
 }
 
@@ -30,6 +33,10 @@ func (r *MemoryRegistryRepository) Create(ctx context.Context, entry *domain.Reg
 	if _, exists := r.store[entry.AgentID]; exists {
 		return errors.New("agent with this ID already exists")
 	}
+	if owner, bound := r.owners[entry.AgentID]; bound && owner != entry.Owner {
+		return errors.New("permission denied")
+	}
+	r.owners[entry.AgentID] = entry.Owner
 
 	// Store a copy to prevent external mutation
 	entryCopy := *entry
@@ -76,6 +83,26 @@ func (r *MemoryRegistryRepository) Delete(ctx context.Context, agentID string) e
 	return nil
 }
 
+func (r *MemoryRegistryRepository) DeleteStale(ctx context.Context, agentID string, cutoff time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, exists := r.store[agentID]
+	if !exists {
+		return false, nil
+	}
+	lastSeen := entry.RegisteredAt
+	if entry.LastHeartbeat != nil {
+		lastSeen = *entry.LastHeartbeat
+	}
+	if !lastSeen.Before(cutoff) {
+		return false, nil
+	}
+
+	delete(r.store, agentID)
+	return true, nil
+}
+
 func (r *MemoryRegistryRepository) List(ctx context.Context, limit, offset int, filters map[string]interface{}) ([]*domain.RegistryEntry, int, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -102,7 +129,7 @@ func (r *MemoryRegistryRepository) List(ctx context.Context, limit, offset int,
 	}
 
 	end := offset + limit
-	if end > total {
+	if limit < 0 || end > total {
 		end = total
 	}
 
@@ -122,6 +149,11 @@ func (r *MemoryRegistryRepository) UpdateHeartbeat(ctx context.Context, agentID
 	return nil
 }
 
+func (r *MemoryRegistryRepository) Ping(ctx context.Context) error {
+	// The in-memory store is always available while the process is running.
+	return ctx.Err()
+}
+
 // Helper to match filters
 func matchesFilters(entry *domain.RegistryEntry, filters map[string]interface{}) bool {
 	// Tags filter (array overlap)
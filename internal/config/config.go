@@ -0,0 +1,281 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the configuration for the registry server (cmd/server).
+// Values are resolved in order of increasing precedence: defaults, YAML file, environment, flags.
+type Config struct {
+	HTTP    ListenerConfig `yaml:"http"`
+	GRPC    ListenerConfig `yaml:"grpc"`
+	Storage StorageConfig  `yaml:"storage"`
+	TLS     TLSConfig      `yaml:"tls"`
+	Auth    AuthConfig     `yaml:"auth"`
+
+	// HeartbeatTTL removes agents that have not sent a heartbeat for this long. Zero disables expiry.
+	HeartbeatTTL time.Duration `yaml:"heartbeatTTL"`
+	// HealthCheckInterval is how often repository readiness is probed for grpc.health.v1.
+	HealthCheckInterval time.Duration `yaml:"healthCheckInterval"`
+	// ShutdownTimeout bounds the graceful drain of both listeners after a signal.
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout"`
+	// TraceExporter selects the OpenTelemetry exporter (see pkg/telemetry).
+	TraceExporter string `yaml:"traceExporter"`
+
+	// ValidateOnly reports whether --validate-config was passed.
+	ValidateOnly bool `yaml:"-"`
+}
+
+type ListenerConfig struct {
+	Port int `yaml:"port"`
+}
+
+type StorageConfig struct {
+	// Backend selects the repository implementation. Only "memory" is available today.
+	Backend string `yaml:"backend"`
+}
+
+type TLSConfig struct {
+	// CertFile and KeyFile enable TLS on both listeners when set.
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+	// ClientCAFile additionally verifies the client certificates callers present (mTLS).
+	// Auth mode mtls requires one on every call but the health checks.
+	ClientCAFile string `yaml:"clientCAFile"`
+}
+
+// Enabled reports whether the listeners should serve TLS.
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != "" || t.KeyFile != ""
+}
+
+// ServerConfig builds the tls.Config shared by the HTTP and gRPC listeners.
+func (t TLSConfig) ServerConfig() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %w", err)
+	}
+
+	conf := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if t.ClientCAFile != "" {
+		pem, err := os.ReadFile(t.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("failed to add client CA's certificate")
+		}
+		conf.ClientCAs = pool
+		// Probes connect without a certificate, so requiring one is left to the
+		// auth middleware and interceptors, which exempt the health checks.
+		conf.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return conf, nil
+}
+
+type AuthConfig struct {
+	// Mode selects how callers are identified: "none" (everyone is anonymous) or
+	// "mtls" (the verified client certificate identifies the caller).
+	Mode string `yaml:"mode"`
+	// Principals maps client certificate Common Names to registry principal names.
+	// Identities without an entry are used as the principal name directly.
+	Principals map[string]string `yaml:"principals"`
+	// Admins lists principals allowed to modify agents owned by others.
+	Admins []string `yaml:"admins"`
+}
+
+// Default returns the configuration used when nothing else is specified.
+func Default() *Config {
+	return &Config{
+		HTTP:                ListenerConfig{Port: 3000},
+		GRPC:                ListenerConfig{Port: 50051},
+		Storage:             StorageConfig{Backend: "memory"},
+		Auth:                AuthConfig{Mode: "none"},
+		HealthCheckInterval: 10 * time.Second,
+		ShutdownTimeout:     15 * time.Second,
+		TraceExporter:       "none",
+	}
+}
+
+// Load builds the configuration from command-line args, the environment and an optional YAML file.
+func Load(args []string) (*Config, error) {
+	cfg := Default()
+
+	// The first parse only finds the config file and rejects bad flags; the flags are
+	// parsed again over the file and environment so that they take precedence.
+	scratch := *cfg
+	fs, configFile := newFlagSet(&scratch)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *configFile != "" {
+		data, err := os.ReadFile(*configFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", *configFile, err)
+		}
+	}
+
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
+	}
+
+	fs, _ = newFlagSet(cfg)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// newFlagSet binds the command-line flags to the fields of cfg, keeping their current
+// values as defaults, and returns the flag set with the --config path.
+func newFlagSet(cfg *Config) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("AGENTMESH_REGISTRY_CONFIG"), "path to a YAML configuration file")
+	fs.BoolVar(&cfg.ValidateOnly, "validate-config", cfg.ValidateOnly, "validate the configuration and exit")
+	fs.IntVar(&cfg.HTTP.Port, "http-port", cfg.HTTP.Port, "HTTP listen port")
+	fs.IntVar(&cfg.GRPC.Port, "grpc-port", cfg.GRPC.Port, "gRPC listen port")
+	fs.StringVar(&cfg.Storage.Backend, "storage", cfg.Storage.Backend, "storage backend (memory)")
+	fs.StringVar(&cfg.TLS.CertFile, "tls-cert", cfg.TLS.CertFile, "TLS certificate file")
+	fs.StringVar(&cfg.TLS.KeyFile, "tls-key", cfg.TLS.KeyFile, "TLS private key file")
+	fs.StringVar(&cfg.TLS.ClientCAFile, "tls-client-ca", cfg.TLS.ClientCAFile, "CA file used to verify client certificates")
+	fs.StringVar(&cfg.Auth.Mode, "auth", cfg.Auth.Mode, "caller authentication mode (none, mtls)")
+	fs.DurationVar(&cfg.HeartbeatTTL, "heartbeat-ttl", cfg.HeartbeatTTL, "expire agents without a heartbeat for this long (0 disables)")
+	fs.DurationVar(&cfg.HealthCheckInterval, "health-interval", cfg.HealthCheckInterval, "readiness probe interval")
+	fs.DurationVar(&cfg.ShutdownTimeout, "shutdown-timeout", cfg.ShutdownTimeout, "graceful shutdown deadline")
+	fs.StringVar(&cfg.TraceExporter, "trace-exporter", cfg.TraceExporter, "trace exporter (none, stdout, otlp)")
+	return fs, configFile
+}
+
+// applyEnv overlays environment variables. PORT and GRPC_PORT are kept for backward compatibility.
+func applyEnv(cfg *Config) error {
+	// Later entries win, so the namespaced variables override the legacy ones.
+	ports := []struct {
+		key string
+		dst *int
+	}{
+		{"PORT", &cfg.HTTP.Port},
+		{"GRPC_PORT", &cfg.GRPC.Port},
+		{"AGENTMESH_REGISTRY_HTTP_PORT", &cfg.HTTP.Port},
+		{"AGENTMESH_REGISTRY_GRPC_PORT", &cfg.GRPC.Port},
+	}
+	for _, p := range ports {
+		if v := os.Getenv(p.key); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", p.key, err)
+			}
+			*p.dst = n
+		}
+	}
+
+	strs := map[string]*string{
+		"AGENTMESH_REGISTRY_STORAGE":       &cfg.Storage.Backend,
+		"AGENTMESH_REGISTRY_TLS_CERT":      &cfg.TLS.CertFile,
+		"AGENTMESH_REGISTRY_TLS_KEY":       &cfg.TLS.KeyFile,
+		"AGENTMESH_REGISTRY_TLS_CLIENT_CA": &cfg.TLS.ClientCAFile,
+		"AGENTMESH_REGISTRY_AUTH":          &cfg.Auth.Mode,
+		"AGENTMESH_TRACE_EXPORTER":         &cfg.TraceExporter,
+	}
+	for key, dst := range strs {
+		if v := os.Getenv(key); v != "" {
+			*dst = v
+		}
+	}
+
+	durations := map[string]*time.Duration{
+		"AGENTMESH_REGISTRY_HEARTBEAT_TTL":    &cfg.HeartbeatTTL,
+		"AGENTMESH_REGISTRY_HEALTH_INTERVAL":  &cfg.HealthCheckInterval,
+		"AGENTMESH_REGISTRY_SHUTDOWN_TIMEOUT": &cfg.ShutdownTimeout,
+	}
+	for key, dst := range durations {
+		if v := os.Getenv(key); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", key, err)
+			}
+			*dst = d
+		}
+	}
+
+	return nil
+}
+
+// Validate checks the configuration for inconsistencies and missing files.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.HTTP.Port <= 0 || c.HTTP.Port > 65535 {
+		errs = append(errs, fmt.Errorf("http port %d out of range", c.HTTP.Port))
+	}
+	if c.GRPC.Port <= 0 || c.GRPC.Port > 65535 {
+		errs = append(errs, fmt.Errorf("grpc port %d out of range", c.GRPC.Port))
+	}
+	if c.HTTP.Port == c.GRPC.Port {
+		errs = append(errs, fmt.Errorf("http and grpc ports must differ (both %d)", c.HTTP.Port))
+	}
+
+	if c.Storage.Backend != "memory" {
+		errs = append(errs, fmt.Errorf("unsupported storage backend: %q", c.Storage.Backend))
+	}
+
+	if c.TLS.Enabled() {
+		if c.TLS.CertFile == "" || c.TLS.KeyFile == "" {
+			errs = append(errs, errors.New("tls requires both certFile and keyFile"))
+		}
+		for _, f := range []string{c.TLS.CertFile, c.TLS.KeyFile, c.TLS.ClientCAFile} {
+			if f == "" {
+				continue
+			}
+			if _, err := os.Stat(f); err != nil {
+				errs = append(errs, fmt.Errorf("tls file: %w", err))
+			}
+		}
+	} else if c.TLS.ClientCAFile != "" {
+		errs = append(errs, errors.New("tls clientCAFile requires certFile and keyFile"))
+	}
+
+	switch c.Auth.Mode {
+	case "none":
+	case "mtls":
+		if c.TLS.ClientCAFile == "" {
+			errs = append(errs, errors.New("auth mode mtls requires tls clientCAFile"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unsupported auth mode: %q", c.Auth.Mode))
+	}
+
+	if c.HeartbeatTTL < 0 {
+		errs = append(errs, errors.New("heartbeatTTL must not be negative"))
+	}
+	if c.HealthCheckInterval <= 0 {
+		errs = append(errs, errors.New("healthCheckInterval must be positive"))
+	}
+	if c.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("shutdownTimeout must be positive"))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "context"
+
+// Principal identifies the authenticated caller of a registry operation.
+type Principal struct {
+	// Name is the registry identity of the caller, recorded as the Owner of agents it registers.
+	Name string
+	// Admin principals may modify agents owned by anyone.
+	Admin bool
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the authenticated principal.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal attached by the transport, if any.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
@@ -9,10 +9,21 @@ import (
 
 // RegistryRepository defines the interface for storage operations.
 type RegistryRepository interface {
+	// Create stores a new entry. An agent ID stays bound to the owner that first
+	// registered it, even once deleted: Create fails with "permission denied" when
+	// entry.Owner is someone else.
 	Create(ctx context.Context, entry *domain.RegistryEntry) error
 	Get(ctx context.Context, agentID string) (*domain.RegistryEntry, error)
 	Update(ctx context.Context, entry *domain.RegistryEntry) error
 	Delete(ctx context.Context, agentID string) error
+	// DeleteStale deletes the agent if its last heartbeat (or registration, if none)
+	// is before cutoff, and reports whether it did. The check and the delete are atomic,
+	// so an agent heartbeating meanwhile is kept.
+	DeleteStale(ctx context.Context, agentID string, cutoff time.Time) (bool, error)
+	// List returns a page of the entries matching filters and their total count.
+	// A negative limit returns all of them.
 	List(ctx context.Context, limit, offset int, filters map[string]interface{}) ([]*domain.RegistryEntry, int, error)
 	UpdateHeartbeat(ctx context.Context, agentID string, timestamp time.Time) error
+	// Ping reports whether the underlying storage is reachable and usable.
+	Ping(ctx context.Context) error
 }
@@ -15,4 +15,8 @@ type RegistryService interface {
 	DeleteAgent(ctx context.Context, agentID string) error
 	ListAgents(ctx context.Context, limit, offset int, filters map[string]interface{}) ([]*domain.RegistryEntry, int, error)
 	Heartbeat(ctx context.Context, agentID string) (*time.Time, error)
+	// ExpireAgents deletes agents whose last heartbeat (or registration, if none) is before cutoff.
+	ExpireAgents(ctx context.Context, cutoff time.Time) ([]string, error)
+	// CheckReadiness returns an error if the service cannot currently serve requests.
+	CheckReadiness(ctx context.Context) error
 }
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -56,6 +57,9 @@ func (s *RegistryServiceImpl) UpdateAgent(ctx context.Context, agentID string, a
 	if err != nil {
 		return nil, err
 	}
+	if err := authorize(ctx, existing); err != nil {
+		return nil, err
+	}
 
 	existing.AgentCard = agentCard
 	existing.Tags = tags
@@ -70,6 +74,14 @@ func (s *RegistryServiceImpl) UpdateAgent(ctx context.Context, agentID string, a
 }
 
 func (s *RegistryServiceImpl) DeleteAgent(ctx context.Context, agentID string) error {
+	existing, err := s.repo.Get(ctx, agentID)
+	if err != nil {
+		return err
+	}
+	if err := authorize(ctx, existing); err != nil {
+		return err
+	}
+
 	return s.repo.Delete(ctx, agentID)
 }
 
@@ -78,9 +90,51 @@ func (s *RegistryServiceImpl) ListAgents(ctx context.Context, limit, offset int,
 }
 
 func (s *RegistryServiceImpl) Heartbeat(ctx context.Context, agentID string) (*time.Time, error) {
+	existing, err := s.repo.Get(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(ctx, existing); err != nil {
+		return nil, err
+	}
+
 	now := time.Now()
 	if err := s.repo.UpdateHeartbeat(ctx, agentID, now); err != nil {
 		return nil, err
 	}
 	return &now, nil
 }
+
+func (s *RegistryServiceImpl) ExpireAgents(ctx context.Context, cutoff time.Time) ([]string, error) {
+	entries, _, err := s.repo.List(ctx, -1, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var expired []string
+	for _, entry := range entries {
+		deleted, err := s.repo.DeleteStale(ctx, entry.AgentID, cutoff)
+		if err != nil {
+			return expired, err
+		}
+		if deleted {
+			expired = append(expired, entry.AgentID)
+		}
+	}
+
+	return expired, nil
+}
+
+func (s *RegistryServiceImpl) CheckReadiness(ctx context.Context) error {
+	return s.repo.Ping(ctx)
+}
+
+// authorize checks that the authenticated principal, if any, owns the entry.
+// Requests without a principal (auth mode "none") are always allowed.
+func authorize(ctx context.Context, entry *domain.RegistryEntry) error {
+	p, ok := domain.PrincipalFromContext(ctx)
+	if !ok || p.Admin || p.Name == entry.Owner {
+		return nil
+	}
+	return errors.New("permission denied")
+}
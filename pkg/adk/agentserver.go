@@ -0,0 +1,342 @@
+package adk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+	registry "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/registry"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/telemetry"
+	"google.golang.org/adk/agent"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const (
+	// defaultAgentAddr is where the Sidecar expects the local agent by default.
+	defaultAgentAddr       = "localhost:50054"
+	defaultShutdownTimeout = 30 * time.Second
+	// agentCardPath is where the card is served, as the A2A well-known URI.
+	agentCardPath = "/.well-known/agent-card.json"
+)
+
+// AgentServerConfig configures an AgentServer.
+type AgentServerConfig struct {
+	// Agents are the ADK agents hosted by the server. The first one also handles
+	// tasks that do not name a hosted agent.
+	Agents []agent.Agent
+	// AgentID is the DID the agents are registered under: the AgentID of their Sidecar,
+	// which passes it on as target_agent_id. Without it, any DID is taken to be the
+	// server's.
+	AgentID string
+	// Listener is the listener to serve on. Without it, the server listens on Addr.
+	Listener net.Listener
+	// Addr is the address to listen on. Defaults to localhost:50054.
+	Addr string
+	// Agent configures how every agent is served, e.g. its session service.
+	Agent ServerConfig
+	// ServerOptions are passed to grpc.NewServer, after the telemetry option.
+	ServerOptions []grpc.ServerOption
+	// UnaryInterceptors and StreamInterceptors are chained in order, after telemetry.
+	UnaryInterceptors  []grpc.UnaryServerInterceptor
+	StreamInterceptors []grpc.StreamServerInterceptor
+	// Card describes the hosted agents in the card returned by Card: the card of the
+	// first agent, carrying the skills of all of them.
+	Card AgentCardConfig
+	// CardAddr is the address to serve the card on over HTTP while the server runs,
+	// for the Sidecar's --agent-card-url (see CardURL). Empty serves no card.
+	CardAddr string
+	// ShutdownTimeout is how long Serve waits for running tasks once its context is
+	// done before stopping the server, which cancels them. Defaults to 30s.
+	ShutdownTimeout time.Duration
+}
+
+// AgentServer is a gRPC server hosting one or more ADK agents for the Sidecar.
+// A task goes to the agent named by its target_agent_id or x-target-skill metadata,
+// where an agent is named by its name or the ID of one of its skills.
+type AgentServer struct {
+	mesh.UnimplementedA2AMeshServiceServer
+	hosted          []*hostedAgent
+	agentID         string
+	shutdownTimeout time.Duration
+	card            *registry.AgentCard
+	lis             net.Listener
+	cardLis         net.Listener
+	grpc            *grpc.Server
+}
+
+// hostedAgent is an agent served by an AgentServer.
+type hostedAgent struct {
+	wrapper *ServerWrapper
+	card    *registry.AgentCard
+}
+
+// NewAgentServer creates a server for cfg.Agents and starts listening.
+// Call Serve to handle tasks.
+func NewAgentServer(cfg AgentServerConfig) (*AgentServer, error) {
+	if len(cfg.Agents) == 0 {
+		return nil, errors.New("at least one agent is required")
+	}
+
+	s := &AgentServer{agentID: cfg.AgentID, shutdownTimeout: cfg.ShutdownTimeout, lis: cfg.Listener}
+	if s.shutdownTimeout <= 0 {
+		s.shutdownTimeout = defaultShutdownTimeout
+	}
+	names := make(map[string]bool)
+	for _, a := range cfg.Agents {
+		if names[a.Name()] {
+			return nil, fmt.Errorf("duplicate agent name %q", a.Name())
+		}
+		names[a.Name()] = true
+		s.hosted = append(s.hosted, &hostedAgent{
+			wrapper: NewServerWrapperWithConfig(a, cfg.Agent),
+			card:    agentCard(a),
+		})
+	}
+
+	if s.lis == nil {
+		addr := cfg.Addr
+		if addr == "" {
+			addr = defaultAgentAddr
+		}
+		lis, err := net.Listen("tcp", addr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+		}
+		s.lis = lis
+	}
+
+	var skills []*registry.AgentSkill
+	for _, h := range s.hosted[1:] {
+		skills = append(skills, h.card.Skills...)
+	}
+	cardCfg := cfg.Card
+	cardCfg.Skills = slices.Concat(skills, cfg.Card.Skills)
+	s.card = NewAgentCard(cfg.Agents[0], cardCfg)
+
+	if cfg.CardAddr != "" {
+		lis, err := net.Listen("tcp", cfg.CardAddr)
+		if err != nil {
+			s.lis.Close()
+			return nil, fmt.Errorf("failed to listen on %s: %w", cfg.CardAddr, err)
+		}
+		s.cardLis = lis
+	}
+
+	opts := append([]grpc.ServerOption{telemetry.ServerOption()}, cfg.ServerOptions...)
+	if len(cfg.UnaryInterceptors) > 0 {
+		opts = append(opts, grpc.ChainUnaryInterceptor(cfg.UnaryInterceptors...))
+	}
+	if len(cfg.StreamInterceptors) > 0 {
+		opts = append(opts, grpc.ChainStreamInterceptor(cfg.StreamInterceptors...))
+	}
+	s.grpc = grpc.NewServer(opts...)
+	mesh.RegisterA2AMeshServiceServer(s.grpc, s)
+	return s, nil
+}
+
+// Addr returns the address the server listens on.
+func (s *AgentServer) Addr() net.Addr {
+	return s.lis.Addr()
+}
+
+// Card returns the card describing the hosted agents, without a DID: the Sidecar
+// registers it under its AgentID.
+func (s *AgentServer) Card() *registry.AgentCard {
+	return s.card
+}
+
+// CardURL returns the URL the card is served on, to pass as the Sidecar's
+// --agent-card-url, or "" without a CardAddr.
+func (s *AgentServer) CardURL() string {
+	if s.cardLis == nil {
+		return ""
+	}
+	return "http://" + s.cardLis.Addr().String() + agentCardPath
+}
+
+// CardHandler returns an HTTP handler serving the card as JSON, for serving it on a
+// server of your own.
+func (s *AgentServer) CardHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := protojson.Marshal(s.card)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+}
+
+// AgentCards returns the AgentCards of the hosted agents, in the order of
+// AgentServerConfig.Agents.
+func (s *AgentServer) AgentCards() []*registry.AgentCard {
+	cards := make([]*registry.AgentCard, len(s.hosted))
+	for i, h := range s.hosted {
+		cards[i] = h.card
+	}
+	return cards
+}
+
+// Serve handles tasks until ctx is done, then stops gracefully: it waits up to
+// ShutdownTimeout for running tasks to end and cancels those still running. It
+// returns nil once stopped. While the server runs, idle sessions are expired and,
+// with a CardAddr, the card is served.
+func (s *AgentServer) Serve(ctx context.Context) error {
+	sweepCtx, stopSweep := context.WithCancel(ctx)
+	var sweeps sync.WaitGroup
+	for _, h := range s.hosted {
+		sweeps.Add(1)
+		go func() {
+			defer sweeps.Done()
+			h.wrapper.Run(sweepCtx)
+		}()
+	}
+	defer func() { stopSweep(); sweeps.Wait() }()
+
+	if s.cardLis != nil {
+		mux := http.NewServeMux()
+		mux.Handle(agentCardPath, s.CardHandler())
+		cards := &http.Server{Handler: mux}
+		go cards.Serve(s.cardLis)
+		defer cards.Close()
+	}
+
+	stopped, shutdown := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(shutdown)
+		select {
+		case <-ctx.Done():
+			s.shutdown()
+		case <-stopped:
+		}
+	}()
+
+	log.Printf("Agent server listening on %s", s.lis.Addr())
+	err := s.grpc.Serve(s.lis)
+	close(stopped)
+	<-shutdown
+	if err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+// shutdown stops the server gracefully, and outright once shutdownTimeout has passed.
+func (s *AgentServer) shutdown() {
+	timer := time.AfterFunc(s.shutdownTimeout, func() {
+		log.Printf("Tasks still running after %s, stopping agent server", s.shutdownTimeout)
+		s.grpc.Stop()
+	})
+	defer timer.Stop()
+	s.grpc.GracefulStop()
+}
+
+// StreamTask hands the stream to the agent named by its first TaskStart.
+func (s *AgentServer) StreamTask(stream mesh.A2AMeshService_StreamTaskServer) error {
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	req := first.GetTaskStart().GetRequest()
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	h, target := s.route(req, md)
+	if h == nil {
+		return stream.Send(&mesh.StreamEvent{
+			Event: &mesh.StreamEvent_StatusUpdate{
+				StatusUpdate: &mesh.TaskStatusUpdate{
+					TaskId:  req.GetTaskId(),
+					Status:  mesh.Task_REJECTED,
+					Message: "no hosted agent for " + target,
+					Error:   &mesh.TaskError{Code: grpccodes.NotFound.String(), Message: "no hosted agent for " + target},
+				},
+			},
+		})
+	}
+	return h.wrapper.StreamTask(&replayStream{A2AMeshService_StreamTaskServer: stream, first: first})
+}
+
+// CancelTask cancels the task on the agent running it.
+func (s *AgentServer) CancelTask(ctx context.Context, req *mesh.CancelTaskRequest) (*emptypb.Empty, error) {
+	for _, h := range s.hosted {
+		resp, err := h.wrapper.CancelTask(ctx, req)
+		if status.Code(err) != grpccodes.NotFound {
+			return resp, err
+		}
+	}
+	return nil, status.Errorf(grpccodes.NotFound, "unknown task: %s", req.Id)
+}
+
+// route picks the agent for req: the one running its task if it continues one, then
+// the one named by target_agent_id or, when that is empty or the server's DID, by
+// x-target-skill. A task naming no agent goes to the first agent; for one naming an
+// unknown target, route returns nil and the target.
+func (s *AgentServer) route(req *mesh.TaskSendRequest, md metadata.MD) (*hostedAgent, string) {
+	if taskID := req.GetTaskId(); taskID != "" {
+		for _, h := range s.hosted {
+			if h.wrapper.hasTask(taskID) {
+				return h, ""
+			}
+		}
+	}
+
+	target := req.GetTargetAgentId()
+	if s.isServerID(target) {
+		target = ""
+	}
+	if skills := md.Get("x-target-skill"); target == "" && len(skills) > 0 {
+		target = skills[0]
+	}
+	if target == "" {
+		return s.hosted[0], ""
+	}
+	for _, h := range s.hosted {
+		if h.card.Name == target || slices.ContainsFunc(h.card.Skills, func(skill *registry.AgentSkill) bool {
+			return skill.Id == target
+		}) {
+			return h, ""
+		}
+	}
+	return nil, target
+}
+
+// isServerID reports whether target is the DID the agents are registered under.
+// Agent names cannot be DIDs, so without an AgentID any DID is the server's.
+func (s *AgentServer) isServerID(target string) bool {
+	if s.agentID != "" {
+		return target == s.agentID
+	}
+	return strings.HasPrefix(target, "did:")
+}
+
+// replayStream is a task stream whose first event was already read.
+type replayStream struct {
+	mesh.A2AMeshService_StreamTaskServer
+	first *mesh.StreamEvent
+}
+
+func (r *replayStream) Recv() (*mesh.StreamEvent, error) {
+	if first := r.first; first != nil {
+		r.first = nil
+		return first, nil
+	}
+	return r.A2AMeshService_StreamTaskServer.Recv()
+}
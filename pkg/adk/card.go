@@ -0,0 +1,98 @@
+package adk
+
+import (
+	"slices"
+
+	registry "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/registry"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/tool"
+	"google.golang.org/genai"
+)
+
+// AgentCardConfig describes what NewAgentCard cannot derive from the agent, and
+// overrides what it does.
+type AgentCardConfig struct {
+	// Name and Description replace the agent's name and description.
+	Name        string
+	Description string
+	// Version, DocumentationURL and Provider are copied to the card.
+	Version          string
+	DocumentationURL string
+	Provider         *registry.AgentProvider
+	// Tools are the agent's tools, e.g. the Tools of its llmagent.Config; each one is
+	// offered as a skill.
+	Tools []tool.Tool
+	// InputSchema and OutputSchema are the agent's input and output schemas, e.g. those
+	// of its llmagent.Config.
+	InputSchema  *genai.Schema
+	OutputSchema *genai.Schema
+	// InputModes and OutputModes replace the card's default MIME types: text/plain,
+	// plus application/json when there is an input or output schema.
+	InputModes  []string
+	OutputModes []string
+	// Skills are added to the generated skills, replacing those with the same ID.
+	Skills []*registry.AgentSkill
+}
+
+// NewAgentCard describes a as an AgentCard, so that an agent does not have to be
+// described again for the registry. The card has a skill for the agent itself, one
+// for each of its sub-agents and one for each of cfg.Tools. The card has no DID;
+// the agent's Sidecar sets it.
+func NewAgentCard(a agent.Agent, cfg AgentCardConfig) *registry.AgentCard {
+	card := &registry.AgentCard{
+		Name:               a.Name(),
+		Description:        a.Description(),
+		Version:            cfg.Version,
+		DocumentationUrl:   cfg.DocumentationURL,
+		Provider:           cfg.Provider,
+		ProtocolVersion:    "1.0",
+		Capabilities:       &registry.AgentCapabilities{Streaming: true},
+		DefaultInputModes:  cfg.InputModes,
+		DefaultOutputModes: cfg.OutputModes,
+	}
+	if cfg.Name != "" {
+		card.Name = cfg.Name
+	}
+	if cfg.Description != "" {
+		card.Description = cfg.Description
+	}
+	if card.DefaultInputModes == nil {
+		card.DefaultInputModes = modes(cfg.InputSchema != nil)
+	}
+	if card.DefaultOutputModes == nil {
+		card.DefaultOutputModes = modes(cfg.OutputSchema != nil)
+	}
+
+	addSkill := func(skill *registry.AgentSkill) {
+		i := slices.IndexFunc(card.Skills, func(s *registry.AgentSkill) bool { return s.Id == skill.Id })
+		if i < 0 {
+			card.Skills = append(card.Skills, skill)
+		} else {
+			card.Skills[i] = skill
+		}
+	}
+	addSkill(&registry.AgentSkill{Id: a.Name(), Name: a.Name(), Description: card.Description})
+	for _, sub := range a.SubAgents() {
+		addSkill(&registry.AgentSkill{Id: sub.Name(), Name: sub.Name(), Description: sub.Description()})
+	}
+	for _, t := range cfg.Tools {
+		addSkill(&registry.AgentSkill{Id: t.Name(), Name: t.Name(), Description: t.Description()})
+	}
+	for _, skill := range cfg.Skills {
+		addSkill(skill)
+	}
+	return card
+}
+
+// agentCard is the card of a hosted agent without overrides.
+func agentCard(a agent.Agent) *registry.AgentCard {
+	return NewAgentCard(a, AgentCardConfig{})
+}
+
+// modes returns the MIME types of an agent's input or output.
+func modes(schema bool) []string {
+	if schema {
+		return []string{"text/plain", "application/json"}
+	}
+	return []string{"text/plain"}
+}
@@ -0,0 +1,127 @@
+package adk
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/meshconn"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/telemetry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// MeshClient is a connection to the local Sidecar that remote tools and agents share,
+// so that calls reuse one HTTP/2 connection. It is dialed on first use.
+type MeshClient struct {
+	addr string
+
+	mu   sync.Mutex
+	conn *grpc.ClientConn
+}
+
+// NewMeshClient creates a client for the Sidecar listening on addr.
+func NewMeshClient(addr string) *MeshClient {
+	return &MeshClient{addr: addr}
+}
+
+// ClientPool shares a MeshClient per Sidecar address. Its owner closes it once the
+// remote tools and agents using it are done.
+type ClientPool struct {
+	mu      sync.Mutex
+	clients map[string]*MeshClient
+}
+
+// NewClientPool creates an empty pool.
+func NewClientPool() *ClientPool {
+	return &ClientPool{clients: make(map[string]*MeshClient)}
+}
+
+// DefaultClientPool holds the clients of remote tools and agents configured without
+// a Client. Close it when shutting down to close their connections.
+var DefaultClientPool = NewClientPool()
+
+// Client returns the pool's client for the Sidecar listening on addr.
+func (p *ClientPool) Client(addr string) *MeshClient {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.clients == nil {
+		p.clients = make(map[string]*MeshClient)
+	}
+	c, ok := p.clients[addr]
+	if !ok {
+		c = NewMeshClient(addr)
+		p.clients[addr] = c
+	}
+	return c
+}
+
+// Close closes the connections of all clients in the pool and empties it. Clients
+// handed out before dial again on their next call.
+func (p *ClientPool) Close() error {
+	p.mu.Lock()
+	clients := p.clients
+	p.clients = nil
+	p.mu.Unlock()
+
+	var errs []error
+	for _, c := range clients {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// sidecarClient returns the client of DefaultClientPool for the local Sidecar, whose
+// port is taken from AGENTMESH_SIDECAR_PORT.
+func sidecarClient() *MeshClient {
+	port := os.Getenv("AGENTMESH_SIDECAR_PORT")
+	if port == "" {
+		port = "50052" // Default sidecar local port
+	}
+	return DefaultClientPool.Client("127.0.0.1:" + port)
+}
+
+// Client returns a client on the connection to the Sidecar. A connection that has
+// been closed is replaced, and one that failed is told to reconnect at once rather
+// than after its backoff.
+func (c *MeshClient) Client() (mesh.A2AMeshServiceClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn != nil {
+		switch c.conn.GetState() {
+		case connectivity.Shutdown:
+			c.conn = nil
+		case connectivity.TransientFailure:
+			c.conn.ResetConnectBackoff()
+		}
+	}
+	if c.conn == nil {
+		conn, err := grpc.NewClient(c.addr,
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			meshconn.KeepaliveDialOption(),
+			telemetry.DialOption(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to sidecar: %w", err)
+		}
+		c.conn = conn
+	}
+	return mesh.NewA2AMeshServiceClient(c.conn), nil
+}
+
+// Close closes the connection. The next call dials again.
+func (c *MeshClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
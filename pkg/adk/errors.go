@@ -0,0 +1,111 @@
+package adk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+	"google.golang.org/genai"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TaskError describes why a remote task failed. RemoteTool returns it in the "error"
+// field of its result, so the calling agent sees the code and whether a retry may help.
+type TaskError struct {
+	TaskID string `json:"task_id,omitempty"`
+	// Code is a canonical gRPC code name, e.g. "Unavailable" or "InvalidArgument".
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Retryable bool   `json:"retryable"`
+}
+
+func (e *TaskError) Error() string {
+	retry := "not retryable"
+	if e.Retryable {
+		retry = "retryable"
+	}
+	if e.TaskID == "" {
+		return fmt.Sprintf("remote task failed: %s (%s): %s", e.Code, retry, e.Message)
+	}
+	return fmt.Sprintf("remote task %s failed: %s (%s): %s", e.TaskID, e.Code, retry, e.Message)
+}
+
+// retryable reports whether a failure with code may go away on its own.
+func retryable(code codes.Code) bool {
+	switch code {
+	case codes.Unavailable, codes.ResourceExhausted, codes.DeadlineExceeded, codes.Aborted:
+		return true
+	}
+	return false
+}
+
+// errorCode classifies err: gRPC status codes are kept, and model API errors are
+// mapped from their HTTP status.
+func errorCode(err error) codes.Code {
+	if s, ok := status.FromError(err); ok && s.Code() != codes.Unknown {
+		return s.Code()
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return codes.DeadlineExceeded
+	}
+	if errors.Is(err, context.Canceled) {
+		return codes.Canceled
+	}
+
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.Code == http.StatusTooManyRequests:
+			return codes.ResourceExhausted
+		case apiErr.Code == http.StatusUnauthorized:
+			return codes.Unauthenticated
+		case apiErr.Code == http.StatusForbidden:
+			return codes.PermissionDenied
+		case apiErr.Code == http.StatusNotFound:
+			return codes.NotFound
+		case apiErr.Code == http.StatusServiceUnavailable || apiErr.Code == http.StatusGatewayTimeout:
+			return codes.Unavailable
+		case apiErr.Code >= 400 && apiErr.Code < 500:
+			return codes.InvalidArgument
+		}
+	}
+	return codes.Internal
+}
+
+// toTaskError describes err for a FAILED status update.
+func toTaskError(err error) *mesh.TaskError {
+	code := errorCode(err)
+	return &mesh.TaskError{
+		Code:      code.String(),
+		Message:   err.Error(),
+		Retryable: retryable(code),
+	}
+}
+
+// remoteError builds the TaskError returned by RemoteTool for a task that ended with
+// update, or for a failed call when update is nil.
+func remoteError(taskID string, update *mesh.TaskStatusUpdate, err error) *TaskError {
+	if update == nil {
+		code := errorCode(err)
+		return &TaskError{TaskID: taskID, Code: code.String(), Message: status.Convert(err).Message(), Retryable: retryable(code)}
+	}
+	if e := update.Error; e != nil {
+		return &TaskError{TaskID: taskID, Code: e.Code, Message: e.Message, Retryable: e.Retryable}
+	}
+
+	code := codes.Unknown
+	switch update.Status {
+	case mesh.Task_CANCELED:
+		code = codes.Canceled
+	case mesh.Task_REJECTED:
+		code = codes.FailedPrecondition
+	}
+	message := update.Message
+	if message == "" {
+		message = "task " + update.Status.String()
+	}
+	return &TaskError{TaskID: taskID, Code: code.String(), Message: message, Retryable: retryable(code)}
+}
@@ -0,0 +1,24 @@
+package adk
+
+import (
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+type requestInputArgs struct {
+	Question string `json:"question" jsonschema:"The question for the caller."`
+}
+
+// RequestInputTool creates a tool an agent can call to ask its caller for more information.
+// It is long-running: under ServeAgent the call pauses the task with INPUT_REQUIRED and the
+// question as message, and the caller's answer resumes the run as the tool's response.
+func RequestInputTool() (tool.Tool, error) {
+	return functiontool.New(functiontool.Config{
+		Name:          "request_input",
+		Description:   "Asks the caller a question when more information is needed to complete the task. The answer is returned as the result of this tool.",
+		IsLongRunning: true,
+	}, func(ctx tool.Context, args requestInputArgs) (map[string]any, error) {
+		// Only reached when the agent runs outside ServeAgent; the answer arrives later.
+		return map[string]any{"status": "pending"}, nil
+	})
+}
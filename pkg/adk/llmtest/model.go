@@ -0,0 +1,169 @@
+// Package llmtest provides a scripted model.LLM, to run ADK agents and the
+// AgentMesh bridge without a model API key or network access.
+package llmtest
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"strings"
+	"sync"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// Response is one scripted answer of a Model.
+type Response struct {
+	// Text is the text of the answer.
+	Text string
+	// Chunks splits the text of a streamed answer: each chunk is sent as a partial
+	// response before the full answer. Text defaults to their concatenation.
+	Chunks []string
+	// Calls are the tool calls of the answer.
+	Calls []*genai.FunctionCall
+	// Err fails the model call.
+	Err error
+
+	// reply builds the answer from the request.
+	reply func(req *model.LLMRequest) Response
+}
+
+// Text answers with text.
+func Text(text string) Response {
+	return Response{Text: text}
+}
+
+// Stream answers with the concatenation of chunks, sent chunk by chunk when the
+// agent streams.
+func Stream(chunks ...string) Response {
+	return Response{Chunks: chunks}
+}
+
+// Call answers with a call of the named tool.
+func Call(name string, args map[string]any) Response {
+	return Response{Calls: []*genai.FunctionCall{{Name: name, Args: args}}}
+}
+
+// Fail fails the model call with err.
+func Fail(err error) Response {
+	return Response{Err: err}
+}
+
+// Reply answers with what fn returns for the request, e.g. to repeat a tool's result.
+func Reply(fn func(req *model.LLMRequest) Response) Response {
+	return Response{reply: fn}
+}
+
+// Model is a model.LLM that gives the responses of its script in order, one per call.
+// It fails once the script is exhausted. It is safe for concurrent use.
+type Model struct {
+	name string
+
+	mu       sync.Mutex
+	script   []Response
+	requests []*model.LLMRequest
+}
+
+// New creates a model named name that answers with script.
+func New(name string, script ...Response) *Model {
+	return &Model{name: name, script: script}
+}
+
+// Name implements model.LLM.
+func (m *Model) Name() string {
+	return m.name
+}
+
+// GenerateContent implements model.LLM. It records req and answers with the next
+// response of the script.
+func (m *Model) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	m.mu.Lock()
+	m.requests = append(m.requests, req)
+	n := len(m.requests)
+	var resp Response
+	ok := n <= len(m.script)
+	if ok {
+		resp = m.script[n-1]
+	}
+	m.mu.Unlock()
+
+	return func(yield func(*model.LLMResponse, error) bool) {
+		if !ok {
+			yield(nil, fmt.Errorf("model %s: script exhausted after %d responses", m.name, len(m.script)))
+			return
+		}
+		if resp.reply != nil {
+			resp = resp.reply(req)
+		}
+		if resp.Err != nil {
+			yield(nil, resp.Err)
+			return
+		}
+
+		if stream {
+			for _, chunk := range resp.Chunks {
+				if err := ctx.Err(); err != nil {
+					yield(nil, err)
+					return
+				}
+				partial := &model.LLMResponse{
+					Content: genai.NewContentFromText(chunk, genai.RoleModel),
+					Partial: true,
+				}
+				if !yield(partial, nil) {
+					return
+				}
+			}
+		}
+		yield(resp.content(), nil)
+	}
+}
+
+// Requests returns the requests the model received, in order.
+func (m *Model) Requests() []*model.LLMRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*model.LLMRequest(nil), m.requests...)
+}
+
+// Remaining returns how many responses of the script are left.
+func (m *Model) Remaining() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return max(len(m.script)-len(m.requests), 0)
+}
+
+// content is the complete answer.
+func (r Response) content() *model.LLMResponse {
+	text := r.Text
+	if text == "" {
+		text = strings.Join(r.Chunks, "")
+	}
+
+	content := &genai.Content{Role: genai.RoleModel}
+	if text != "" {
+		content.Parts = append(content.Parts, genai.NewPartFromText(text))
+	}
+	for _, call := range r.Calls {
+		// Copy the call: ADK sets its ID, and a script may be run more than once.
+		content.Parts = append(content.Parts, &genai.Part{FunctionCall: &genai.FunctionCall{
+			ID:   call.ID,
+			Name: call.Name,
+			Args: call.Args,
+		}})
+	}
+	return &model.LLMResponse{Content: content, TurnComplete: true}
+}
+
+// FunctionResponse returns the last tool result in req with the given name, or nil.
+func FunctionResponse(req *model.LLMRequest, name string) *genai.FunctionResponse {
+	for i := len(req.Contents) - 1; i >= 0; i-- {
+		for _, part := range req.Contents[i].Parts {
+			if resp := part.FunctionResponse; resp != nil && resp.Name == name {
+				return resp
+			}
+		}
+	}
+	return nil
+}
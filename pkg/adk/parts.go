@@ -0,0 +1,192 @@
+package adk
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+	"github.com/google/uuid"
+	"google.golang.org/genai"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// defaultMIMEType is assumed for files sent without a MIME type.
+	defaultMIMEType = "application/octet-stream"
+	// jsonMIMEType marks a response that is a JSON object, i.e. structured output.
+	jsonMIMEType = "application/json"
+)
+
+// toContent converts the parts of a mesh message into genai content for role.
+// Text stays text, files become inline or file data and structured data is passed as JSON.
+func toContent(msg *mesh.Message, role genai.Role) (*genai.Content, error) {
+	content := &genai.Content{Role: string(role)}
+	for _, part := range msg.GetParts() {
+		p, err := toPart(part)
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			content.Parts = append(content.Parts, p)
+		}
+	}
+	return content, nil
+}
+
+func toPart(part *mesh.Part) (*genai.Part, error) {
+	switch c := part.Content.(type) {
+	case *mesh.Part_TextPart:
+		return genai.NewPartFromText(c.TextPart), nil
+	case *mesh.Part_FilePart:
+		mimeType := c.FilePart.MimeType
+		if mimeType == "" {
+			mimeType = defaultMIMEType
+		}
+		switch data := c.FilePart.Data.(type) {
+		case *mesh.FilePart_InlineBytes:
+			return genai.NewPartFromBytes(data.InlineBytes, mimeType), nil
+		case *mesh.FilePart_Uri:
+			return genai.NewPartFromURI(data.Uri, mimeType), nil
+		}
+		return nil, fmt.Errorf("file part has neither inline bytes nor a URI")
+	case *mesh.Part_DataPart:
+		data, err := json.Marshal(c.DataPart.AsMap())
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode data part: %w", err)
+		}
+		return genai.NewPartFromText(string(data)), nil
+	}
+	return nil, nil
+}
+
+// toFunctionResponse converts a message answering call into genai content. The first
+// DataPart is the tool's response; without one the text is passed as {"answer": text}.
+// Any files are sent along with the response.
+func toFunctionResponse(call *genai.FunctionCall, msg *mesh.Message) (*genai.Content, error) {
+	var response map[string]any
+	var files []*genai.Part
+	for _, part := range msg.GetParts() {
+		switch c := part.Content.(type) {
+		case *mesh.Part_DataPart:
+			if response == nil {
+				response = c.DataPart.AsMap()
+			}
+		case *mesh.Part_FilePart:
+			p, err := toPart(part)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, p)
+		}
+	}
+	if response == nil {
+		response = map[string]any{"answer": messageText(msg)}
+	}
+
+	content := genai.NewContentFromFunctionResponse(call.Name, response, genai.RoleUser)
+	content.Parts[0].FunctionResponse.ID = call.ID
+	content.Parts = append(content.Parts, files...)
+	return content, nil
+}
+
+// messageText joins the text parts of msg.
+func messageText(msg *mesh.Message) string {
+	var texts []string
+	for _, part := range msg.GetParts() {
+		if txt, ok := part.Content.(*mesh.Part_TextPart); ok {
+			texts = append(texts, txt.TextPart)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// toArtifact converts a model output part carrying a file into an Artifact,
+// or returns nil for any other part.
+func toArtifact(part *genai.Part) *mesh.Artifact {
+	switch {
+	case part.InlineData != nil:
+		artifact := &mesh.Artifact{
+			Id:       uuid.NewString(),
+			Name:     part.InlineData.DisplayName,
+			MimeType: part.InlineData.MIMEType,
+			Content:  &mesh.Artifact_Bytes{Bytes: part.InlineData.Data},
+		}
+		if artifact.Name == "" {
+			artifact.Name = artifact.Id
+		}
+		return artifact
+	case part.FileData != nil:
+		artifact := &mesh.Artifact{
+			Id:       uuid.NewString(),
+			Name:     part.FileData.DisplayName,
+			MimeType: part.FileData.MIMEType,
+			Content:  &mesh.Artifact_Uri{Uri: part.FileData.FileURI},
+		}
+		if artifact.Name == "" {
+			artifact.Name = artifact.Id
+		}
+		return artifact
+	}
+	return nil
+}
+
+// fromPart converts a genai part into a mesh part. Text, inline data and file data are
+// kept; thoughts, function calls and other parts return nil.
+func fromPart(part *genai.Part) *mesh.Part {
+	switch {
+	case part.Thought:
+		return nil
+	case part.Text != "":
+		return &mesh.Part{Content: &mesh.Part_TextPart{TextPart: part.Text}}
+	case part.InlineData != nil:
+		return &mesh.Part{Content: &mesh.Part_FilePart{FilePart: &mesh.FilePart{
+			Data:     &mesh.FilePart_InlineBytes{InlineBytes: part.InlineData.Data},
+			MimeType: part.InlineData.MIMEType,
+		}}}
+	case part.FileData != nil:
+		return &mesh.Part{Content: &mesh.Part_FilePart{FilePart: &mesh.FilePart{
+			Data:     &mesh.FilePart_Uri{Uri: part.FileData.FileURI},
+			MimeType: part.FileData.MIMEType,
+		}}}
+	}
+	return nil
+}
+
+// fromArtifact converts an artifact into a genai part: text and JSON stay text,
+// anything else becomes inline or file data.
+func fromArtifact(artifact *mesh.Artifact) *genai.Part {
+	mimeType := artifact.MimeType
+	if mimeType == "" {
+		mimeType = defaultMIMEType
+	}
+	switch content := artifact.Content.(type) {
+	case *mesh.Artifact_Bytes:
+		if strings.HasPrefix(mimeType, "text/") || mimeType == jsonMIMEType {
+			return genai.NewPartFromText(string(content.Bytes))
+		}
+		part := genai.NewPartFromBytes(content.Bytes, mimeType)
+		part.InlineData.DisplayName = artifact.Name
+		return part
+	case *mesh.Artifact_Uri:
+		part := genai.NewPartFromURI(content.Uri, mimeType)
+		part.FileData.DisplayName = artifact.Name
+		return part
+	}
+	return nil
+}
+
+// toStruct converts v into a Struct through its JSON form, so that any JSON-encodable
+// value, e.g. a []string tool argument, is accepted.
+func toStruct(v map[string]any) (*structpb.Struct, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	st := &structpb.Struct{}
+	if err := protojson.Unmarshal(data, st); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
@@ -0,0 +1,204 @@
+package adk
+
+import (
+	"fmt"
+	"io"
+	"iter"
+
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/lifecycle"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+// RemoteAgentConfig describes the mesh agent a remote agent stands in for.
+type RemoteAgentConfig struct {
+	// Name is the name of the agent in the local agent tree; an llmagent transfers to it
+	// by this name.
+	Name string
+	// Description tells a parent llmagent when to transfer to the agent.
+	Description string
+	// Skill is the skill of the remote agent to run.
+	Skill string
+	// AgentID pins the remote agent. Without it, the Sidecar picks an agent for Skill.
+	AgentID string
+	// Client is the connection to the local Sidecar. Defaults to the one shared by all
+	// remote tools and agents.
+	Client *MeshClient
+}
+
+// NewRemoteAgent creates an ADK agent that runs each invocation as a task on a mesh
+// agent through the local Sidecar, so that the mesh agent can be a sub-agent of an
+// llmagent or a step of a workflow agent.
+//
+// The agent sends what was said in the invocation since it last spoke, starting with
+// the user content, and yields the remote task's response and files as its own events.
+// While the task runs, its status changes, messages and tool events are streamed as
+// partial events, which the runner passes on without keeping them in the session; their
+// custom metadata is that of RemoteToolConfig.OnProgress events, without the tool.
+// All invocations in a session share the remote context, so the mesh agent keeps its
+// conversation. When the mesh agent asks for input, its question ends the invocation
+// and the next invocation of the agent answers it. A failed remote task yields an event
+// with the TaskError's code and message as error.
+func NewRemoteAgent(cfg RemoteAgentConfig) (agent.Agent, error) {
+	if cfg.Skill == "" {
+		return nil, fmt.Errorf("remote agent %s: skill is required", cfg.Name)
+	}
+	remote := newRemoteCaller(cfg.Name, cfg.AgentID, cfg.Skill)
+	if cfg.Client != nil {
+		remote.client = cfg.Client
+	}
+	a := &remoteAgent{
+		remote: remote,
+		// The pending task is kept in session state, so it survives across invocations.
+		taskKey: "agentmesh:" + cfg.Name + ":input_task",
+	}
+	return agent.New(agent.Config{
+		Name:        cfg.Name,
+		Description: cfg.Description,
+		Run:         a.run,
+	})
+}
+
+type remoteAgent struct {
+	remote  *remoteCaller
+	taskKey string
+}
+
+func (a *remoteAgent) run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+	return func(yield func(*session.Event, error) bool) {
+		spanCtx, span := telemetry.Tracer().Start(ctx, "RemoteAgent "+a.remote.name, trace.WithSpanKind(trace.SpanKindClient))
+		span.SetAttributes(attribute.String("agentmesh.target_skill", a.remote.targetSkill))
+		defer span.End()
+
+		// A task waiting for input is continued with this invocation's message.
+		pending, _ := ctx.Session().State().Get(a.taskKey)
+		taskID, _ := pending.(string)
+
+		newEvent := func() *session.Event {
+			evt := session.NewEvent(ctx.InvocationID())
+			evt.Branch = ctx.Branch()
+			return evt
+		}
+		// endTask ends the turn with evt, which no longer leaves a task waiting for input.
+		endTask := func(evt *session.Event) {
+			if taskID != "" {
+				evt.Actions.StateDelta[a.taskKey] = nil
+			}
+			evt.LLMResponse.TurnComplete = true
+		}
+		fail := func(update *mesh.TaskStatusUpdate, cause error) {
+			taskErr := remoteError(taskID, update, cause)
+			span.RecordError(taskErr)
+			span.SetStatus(codes.Error, taskErr.Error())
+			evt := newEvent()
+			evt.LLMResponse.ErrorCode = taskErr.Code
+			evt.LLMResponse.ErrorMessage = taskErr.Message
+			endTask(evt)
+			yield(evt, nil)
+		}
+
+		stream, done, err := a.remote.start(spanCtx, &mesh.TaskSendRequest{
+			TaskId:    taskID,
+			ContextId: ctx.Session().ID(),
+			Message:   a.message(ctx),
+		})
+		if err != nil {
+			fail(nil, err)
+			return
+		}
+		defer done()
+
+		for {
+			event, err := stream.Recv()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				fail(nil, err)
+				return
+			}
+
+			if art := event.GetArtifactUpdate(); art != nil {
+				if part := fromArtifact(art.Artifact); part != nil {
+					evt := newEvent()
+					evt.LLMResponse.Content = &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{part}}
+					if !yield(evt, nil) {
+						return
+					}
+				}
+				continue
+			}
+
+			update := event.GetStatusUpdate()
+			if update == nil {
+				continue
+			}
+			if lifecycle.IsTerminal(update.Status) && update.Status != mesh.Task_COMPLETED {
+				fail(update, nil)
+				return
+			}
+			evt := newEvent()
+			if update.Status != mesh.Task_INPUT_REQUIRED && update.Status != mesh.Task_COMPLETED {
+				describeProgress(evt, update.TaskId, event)
+				if !yield(evt, nil) {
+					return
+				}
+				continue
+			}
+
+			if update.Message != "" {
+				evt.LLMResponse.Content = genai.NewContentFromText(update.Message, genai.RoleModel)
+			}
+			if update.Status == mesh.Task_INPUT_REQUIRED {
+				evt.Actions.StateDelta[a.taskKey] = update.TaskId
+				evt.LLMResponse.TurnComplete = true
+				yield(evt, nil)
+				return
+			}
+			endTask(evt)
+			if evt.LLMResponse.Content != nil || len(evt.Actions.StateDelta) > 0 {
+				yield(evt, nil)
+			}
+			return
+		}
+	}
+}
+
+// message collects what the agent has not heard yet: the events of this invocation
+// after its own last event, which start with the user content. Workflow agents thus
+// pass on the output of earlier steps.
+func (a *remoteAgent) message(ctx agent.InvocationContext) *mesh.Message {
+	var parts []*mesh.Part
+	events := ctx.Session().Events()
+	for i := events.Len() - 1; i >= 0; i-- {
+		evt := events.At(i)
+		if evt.InvocationID != ctx.InvocationID() || evt.Author == a.remote.name {
+			break
+		}
+		if evt.LLMResponse.Content == nil || evt.LLMResponse.Partial {
+			continue
+		}
+		var eventParts []*mesh.Part
+		for _, p := range evt.LLMResponse.Content.Parts {
+			if part := fromPart(p); part != nil {
+				eventParts = append(eventParts, part)
+			}
+		}
+		parts = append(eventParts, parts...)
+	}
+
+	if len(parts) == 0 && ctx.UserContent() != nil {
+		for _, p := range ctx.UserContent().Parts {
+			if part := fromPart(p); part != nil {
+				parts = append(parts, part)
+			}
+		}
+	}
+	return &mesh.Message{Role: "user", Parts: parts}
+}
@@ -1,46 +1,147 @@
 package adk
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
-	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/telemetry"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/runner"
 	"google.golang.org/adk/session"
 	"google.golang.org/genai"
-	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
+// defaultSessionTTL is how long an idle session is kept when ServerConfig.SessionTTL is zero.
+const defaultSessionTTL = time.Hour
+
+// defaultUserID owns the sessions of callers that did not identify themselves.
+const defaultUserID = "user"
+
+// ServerConfig configures a ServerWrapper.
+type ServerConfig struct {
+	// SessionService stores the ADK sessions of the served agent, e.g. a
+	// database-backed service to keep them across restarts. Defaults to session.InMemoryService().
+	SessionService session.Service
+	// SessionTTL is how long a session is kept after its last update while no task
+	// runs in it. Zero uses defaultSessionTTL; a negative value keeps sessions forever.
+	// Sessions are only expired while Run is running.
+	SessionTTL time.Duration
+	// ForwardToolEvents reports the agent's tool calls and their results as WORKING
+	// status updates carrying the call or result as structured data.
+	ForwardToolEvents bool
+	// OutputSchema is the schema of the agent's final response, e.g. the OutputSchema of
+	// its llmagent.Config. With one, a response that is a JSON object is sent as structured
+	// output (application/json); without one, the response is always text.
+	OutputSchema *genai.Schema
+	// StreamingMode is the streaming mode the agent runs in. With agent.StreamingModeSSE,
+	// the model's partial responses reach the caller as WORKING messages as they arrive.
+	StreamingMode agent.StreamingMode
+}
+
 // ServerWrapper acts as the bridge between AgentMesh sidecar and the standard ADK Agent.
 // It implements the A2AMeshService interface.
 type ServerWrapper struct {
 	mesh.UnimplementedA2AMeshServiceServer
 	agent agent.Agent
+	// sessions holds the ADK sessions of all tasks. Tasks from the same caller with the same
+	// ContextId share a session, and a task resumed after INPUT_REQUIRED continues with its
+	// full history.
+	sessions          session.Service
+	sessionTTL        time.Duration
+	forwardToolEvents bool
+	structuredOutput  bool
+	streamingMode     agent.StreamingMode
+
+	// mu guards running, which maps the IDs of in-flight tasks to the cancel func of their run,
+	// paused, which maps the IDs of tasks waiting for input to where they stopped, and
+	// active, which counts the tasks running in each session.
+	mu      sync.Mutex
+	running map[string]context.CancelFunc
+	paused  map[string]pausedTask
+	active  map[sessionKey]int
 }
 
-// ServeAgent starts a gRPC server that listens for AgentMesh tasks and forwards them to the provided ADK agent.
-// This blocks until the server stops.
-func ServeAgent(port int, agent agent.Agent) error {
-	lis, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", port))
-	if err != nil {
-		return fmt.Errorf("failed to listen on port %d: %w", port, err)
-	}
+// sessionKey identifies an ADK session: the caller is the session's user.
+type sessionKey struct {
+	userID    string
+	sessionID string
+}
 
-	s := grpc.NewServer()
-	mesh.RegisterA2AMeshServiceServer(s, &ServerWrapper{agent: agent})
+// pausedTask is a task whose run stopped on a long-running tool call.
+// The caller's next message becomes the response to that call.
+type pausedTask struct {
+	session  sessionKey
+	call     *genai.FunctionCall
+	pausedAt time.Time
+}
 
-	log.Printf("Agent server listening on localhost:%d", port)
-	return s.Serve(lis)
+// NewServerWrapper wraps agent so it can be registered on a gRPC server.
+func NewServerWrapper(agent agent.Agent) *ServerWrapper {
+	return NewServerWrapperWithConfig(agent, ServerConfig{})
+}
+
+// NewServerWrapperWithConfig is NewServerWrapper with a custom session setup.
+func NewServerWrapperWithConfig(agent agent.Agent, cfg ServerConfig) *ServerWrapper {
+	if cfg.SessionService == nil {
+		cfg.SessionService = session.InMemoryService()
+	}
+	if cfg.SessionTTL == 0 {
+		cfg.SessionTTL = defaultSessionTTL
+	}
+	return &ServerWrapper{
+		agent:             agent,
+		sessions:          cfg.SessionService,
+		sessionTTL:        cfg.SessionTTL,
+		forwardToolEvents: cfg.ForwardToolEvents,
+		structuredOutput:  cfg.OutputSchema != nil,
+		streamingMode:     cfg.StreamingMode,
+		running:           make(map[string]context.CancelFunc),
+		paused:            make(map[string]pausedTask),
+		active:            make(map[sessionKey]int),
+	}
+}
+
+// ServeAgent starts a gRPC server on localhost:port that listens for AgentMesh tasks and
+// forwards them to the provided ADK agent, and serves the agent's card over HTTP on
+// localhost:port+1 at /.well-known/agent-card.json, the URL to pass as the Sidecar's
+// --agent-card-url. It blocks until ctx is done, then stops gracefully.
+// Use an AgentServer to host several agents or to configure the server.
+func ServeAgent(ctx context.Context, port int, a agent.Agent) error {
+	s, err := NewAgentServer(AgentServerConfig{
+		Agents:   []agent.Agent{a},
+		Addr:     fmt.Sprintf("localhost:%d", port),
+		CardAddr: fmt.Sprintf("localhost:%d", port+1),
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("Serving the agent card at %s", s.CardURL())
+	return s.Serve(ctx)
 }
 
 // StreamTask handles incoming task streams from the Sidecar.
+// A TaskStart with a task ID continues that task; on a stream whose task is waiting
+// for input, a TaskStart without one is taken as the answer.
 func (s *ServerWrapper) StreamTask(stream mesh.A2AMeshService_StreamTaskServer) error {
-	ctx := stream.Context()
 	log.Println("New incoming task stream")
 
+	// waiting is the task this stream left in INPUT_REQUIRED, if any.
+	var waiting string
 	for {
 		event, err := stream.Recv()
 		if err == io.EOF {
@@ -54,118 +155,16 @@ func (s *ServerWrapper) StreamTask(stream mesh.A2AMeshService_StreamTaskServer)
 		case *mesh.StreamEvent_TaskStart:
 			log.Printf("Received TaskStart: %v", e.TaskStart)
 
-			if msg := e.TaskStart.Request.Message; msg != nil {
-				// 1. Setup Runner and Session
-				// We use an in-memory session for simplicity of this example.
-				// In a real scenario, you might want persistent storage.
-				// Each TaskStart is treated as a new session here or we could wire up SessionID.
-				sessionService := session.InMemoryService()
-
-				r, err := runner.New(runner.Config{
-					AppName:        s.agent.Name(),
-					Agent:          s.agent,
-					SessionService: sessionService,
-				})
-				if err != nil {
-					return fmt.Errorf("failed to create runner: %w", err)
-				}
-
-				// 2. Extract Input as genai.Content
-				var prompt string
-				for _, part := range msg.Parts {
-					if txt, ok := part.Content.(*mesh.Part_TextPart); ok {
-						prompt += txt.TextPart + "\n"
-					}
-					// Handle DataPart (Function Call arguments) if needed.
-					// If the remote tool passed structured data, it might be in DataPart.
-					if data, ok := part.Content.(*mesh.Part_DataPart); ok {
-						// Simplistic handling: convert to string representation or map it.
-						// For now, assuming text-based agents or relying on text serialization.
-						if textVal, ok := data.DataPart.Fields["text"]; ok {
-							prompt += textVal.GetStringValue() + "\n"
-						}
-					}
-				}
-
-				// 3. Run Agent
-				inputContent := &genai.Content{
-					Parts: []*genai.Part{
-						genai.NewPartFromText(prompt),
-					},
-					Role: "user",
-				}
-
-				// Using Task ContextId as SessionID if provided, else generated by runner if left empty?
-				// Runner.Run needs sessionID.
-				sessionID := e.TaskStart.Request.ContextId
-				if sessionID == "" {
-					sessionID = "default-session"
-				}
-
-				// Create Session explicitly
-				_, err = sessionService.Create(ctx, &session.CreateRequest{
-					AppName:   s.agent.Name(),
-					UserID:    "user",
-					SessionID: sessionID,
-				})
-				if err != nil {
-					return fmt.Errorf("failed to create session: %w", err)
-				}
-
-				log.Printf("Running agent with input: %s", prompt)
-
-				// Iterate over events from the runner
-				for evt, err := range r.Run(ctx, "user", sessionID, inputContent, agent.RunConfig{}) {
-					if err != nil {
-						log.Printf("Runner error: %v", err)
-						// Send error status?
-						return err
-					}
-
-					// 4. Stream Response
-					// Convert ADK Session Event to Mesh StreamEvent
-					// We are primarily interested in the final response (Model Response)
-					// or tool calls if we wanted to proxy them back (advanced).
-					// For now, let's stream the text content generated by the model.
-
-					if evt.LLMResponse.Content != nil {
-						for _, part := range evt.LLMResponse.Content.Parts {
-							if part.Text != "" {
-								// Send status update with partial text?
-								// Or wait for final. Let's send accumulated text or chunks.
-								// The 'evt' corresponds to a step.
-								resp := &mesh.StreamEvent{
-									Event: &mesh.StreamEvent_StatusUpdate{
-										StatusUpdate: &mesh.TaskStatusUpdate{
-											TaskId:  e.TaskStart.Request.ContextId,
-											Status:  mesh.Task_WORKING, // Or completed if final
-											Message: part.Text,
-										},
-									},
-								}
-								if err := stream.Send(resp); err != nil {
-									return err
-								}
-							}
-						}
-					}
-
-					// Detect completion using IsFinalResponse (helper not available, check properties)
-					// Or just when loop finishes.
-				}
-
-				// Send Completion
-				resp := &mesh.StreamEvent{
-					Event: &mesh.StreamEvent_StatusUpdate{
-						StatusUpdate: &mesh.TaskStatusUpdate{
-							TaskId: e.TaskStart.Request.ContextId,
-							Status: mesh.Task_COMPLETED,
-						},
-					},
-				}
-				if err := stream.Send(resp); err != nil {
-					return err
-				}
+			req := e.TaskStart.Request
+			if req.GetMessage() == nil {
+				continue
+			}
+			taskID := req.TaskId
+			if taskID == "" {
+				taskID = waiting
+			}
+			if waiting, err = s.runTask(stream, req, taskID); err != nil {
+				return err
 			}
 
 		case *mesh.StreamEvent_StatusUpdate:
@@ -175,3 +174,461 @@ func (s *ServerWrapper) StreamTask(stream mesh.A2AMeshService_StreamTaskServer)
 		}
 	}
 }
+
+// CancelTask stops the run of an in-flight task. The task's stream then reports CANCELED.
+// A task waiting for input is dropped, so it can no longer be resumed.
+func (s *ServerWrapper) CancelTask(ctx context.Context, req *mesh.CancelTaskRequest) (*emptypb.Empty, error) {
+	s.mu.Lock()
+	cancel, ok := s.running[req.Id]
+	_, paused := s.paused[req.Id]
+	delete(s.paused, req.Id)
+	s.mu.Unlock()
+	if paused {
+		log.Printf("Dropped task %s waiting for input", req.Id)
+		return &emptypb.Empty{}, nil
+	}
+	if !ok {
+		return nil, status.Errorf(grpccodes.NotFound, "unknown task: %s", req.Id)
+	}
+
+	log.Printf("Canceling task %s", req.Id)
+	cancel()
+	return &emptypb.Empty{}, nil
+}
+
+// runTask runs the agent on a TaskStart and streams its progress back. An empty taskID
+// starts a new task; otherwise the paused task is resumed with the message as the answer
+// to the tool call it stopped on. The run stops when the stream's context ends, the task
+// is canceled with CancelTask, or a long-running tool asks for input, in which case the
+// task reports INPUT_REQUIRED and its ID is returned.
+func (s *ServerWrapper) runTask(stream mesh.A2AMeshService_StreamTaskServer, req *mesh.TaskSendRequest, taskID string) (string, error) {
+	ctx := stream.Context()
+
+	resume := taskID != ""
+	if !resume {
+		taskID = uuid.NewString()
+	}
+	r, key, inputContent, err := s.prepare(ctx, req, taskID, resume)
+	if err != nil {
+		log.Printf("Task %s failed to start: %v", taskID, err)
+		// The task fails, but the stream stays usable for the caller.
+		return "", sendFailure(stream, taskID, err)
+	}
+
+	// Register the run so CancelTask can stop it, and announce the task ID.
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.track(taskID, cancel)
+	defer s.untrack(taskID)
+	s.acquire(key)
+	defer s.release(key)
+
+	if err := sendStatus(stream, taskID, mesh.Task_WORKING, ""); err != nil {
+		return "", err
+	}
+
+	log.Printf("Running agent with input: %s", messageText(req.Message))
+
+	runCtx, span := telemetry.Tracer().Start(runCtx, "adk.run")
+	span.SetAttributes(
+		attribute.String("adk.agent", s.agent.Name()),
+		attribute.String("adk.session_id", key.sessionID),
+		attribute.String("adk.task_id", taskID),
+	)
+	defer span.End()
+
+	// Iterate over events from the runner
+	var pending *genai.FunctionCall
+	for evt, err := range r.Run(runCtx, key.userID, key.sessionID, inputContent, agent.RunConfig{StreamingMode: s.streamingMode}) {
+		if runCtx.Err() != nil {
+			break
+		}
+		if err != nil {
+			log.Printf("Runner error: %v", err)
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			// The task fails, but the stream stays usable for the caller.
+			return "", sendFailure(stream, taskID, err)
+		}
+
+		// 3. Stream Response
+		// Convert ADK Session Event to Mesh StreamEvents.
+		if evt.LLMResponse.Content != nil {
+			if err := s.streamEvent(stream, taskID, evt); err != nil {
+				return "", err
+			}
+		}
+
+		// A long-running tool call waits for the caller: the run stops here and
+		// resumes once the caller answers.
+		if pending = inputRequest(evt); pending != nil {
+			break
+		}
+	}
+
+	if runCtx.Err() != nil {
+		span.SetStatus(codes.Error, "canceled")
+		// The caller went away: there is nobody left to tell.
+		if ctx.Err() != nil {
+			log.Printf("Task %s canceled by caller", taskID)
+			return "", ctx.Err()
+		}
+		// Canceled with CancelTask: report it on the still open stream.
+		return "", sendStatus(stream, taskID, mesh.Task_CANCELED, "")
+	}
+
+	if pending != nil {
+		s.pause(taskID, pausedTask{session: key, call: pending})
+		log.Printf("Task %s waiting for input", taskID)
+		return taskID, sendStatus(stream, taskID, mesh.Task_INPUT_REQUIRED, question(pending))
+	}
+
+	// Send Completion
+	return "", sendStatus(stream, taskID, mesh.Task_COMPLETED, "")
+}
+
+// prepare sets up the run of a task: its runner, the session it runs in and the input
+// content. A resumed task continues in the session it paused in, with the message as
+// the answer to the tool call it stopped on.
+func (s *ServerWrapper) prepare(ctx context.Context, req *mesh.TaskSendRequest, taskID string, resume bool) (*runner.Runner, sessionKey, *genai.Content, error) {
+	// 1. Setup Runner
+	r, err := runner.New(runner.Config{
+		AppName:        s.agent.Name(),
+		Agent:          s.agent,
+		SessionService: s.sessions,
+	})
+	if err != nil {
+		return nil, sessionKey{}, nil, fmt.Errorf("failed to create runner: %w", err)
+	}
+
+	// 2. Extract Input as genai.Content
+	if resume {
+		p, ok := s.resume(taskID, callerID(ctx))
+		if !ok {
+			// The task finished, expired or belongs to another caller.
+			return nil, sessionKey{}, nil, status.Errorf(grpccodes.NotFound, "unknown task: %s", taskID)
+		}
+		content, err := toFunctionResponse(p.call, req.Message)
+		if err != nil {
+			return nil, sessionKey{}, nil, status.Errorf(grpccodes.InvalidArgument, "invalid message: %v", err)
+		}
+		return r, p.session, content, nil
+	}
+
+	// Tasks sharing a caller and ContextId share a session; otherwise each task gets its own.
+	key := sessionKey{userID: callerID(ctx), sessionID: req.ContextId}
+	if key.sessionID == "" {
+		key.sessionID = taskID
+	}
+	if err := s.ensureSession(ctx, key); err != nil {
+		return nil, sessionKey{}, nil, err
+	}
+	content, err := toContent(req.Message, genai.RoleUser)
+	if err != nil {
+		return nil, sessionKey{}, nil, status.Errorf(grpccodes.InvalidArgument, "invalid message: %v", err)
+	}
+	return r, key, content, nil
+}
+
+// streamEvent forwards the content of a runner event. The text of the final response is
+// sent as a "response" artifact, typed application/json when the agent has an output
+// schema and the text is a JSON object. Any other text, including thoughts and partial
+// chunks, is streamed as WORKING messages. Files become artifacts, and tool calls and
+// results are reported as structured status updates when ForwardToolEvents is set.
+func (s *ServerWrapper) streamEvent(stream mesh.A2AMeshService_StreamTaskServer, taskID string, evt *session.Event) error {
+	// A long-running call ends the run too, but the answer comes after the caller's input.
+	final := evt.IsFinalResponse() && len(evt.LongRunningToolIDs) == 0
+
+	var answer []string
+	for _, part := range evt.LLMResponse.Content.Parts {
+		switch {
+		case part.Text != "" && final && !part.Thought:
+			answer = append(answer, part.Text)
+		case part.Text != "":
+			if err := sendStatus(stream, taskID, mesh.Task_WORKING, part.Text); err != nil {
+				return err
+			}
+		case part.FunctionCall != nil && s.forwardToolEvents:
+			call := part.FunctionCall
+			err := sendToolEvent(stream, taskID, "Calling tool "+call.Name, map[string]any{
+				"type": "tool_call",
+				"id":   call.ID,
+				"name": call.Name,
+				"args": call.Args,
+			})
+			if err != nil {
+				return err
+			}
+		case part.FunctionResponse != nil && s.forwardToolEvents:
+			resp := part.FunctionResponse
+			err := sendToolEvent(stream, taskID, "Tool "+resp.Name+" returned", map[string]any{
+				"type":     "tool_result",
+				"id":       resp.ID,
+				"name":     resp.Name,
+				"response": resp.Response,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if artifact := toArtifact(part); artifact != nil {
+			if err := sendArtifact(stream, taskID, artifact); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(answer) == 0 {
+		return nil
+	}
+	// Each text part is a paragraph of the response.
+	text := strings.Join(answer, "\n")
+	mimeType := "text/plain"
+	if trimmed := strings.TrimSpace(text); s.structuredOutput && strings.HasPrefix(trimmed, "{") && json.Valid([]byte(trimmed)) {
+		mimeType = jsonMIMEType
+	}
+	return sendArtifact(stream, taskID, &mesh.Artifact{
+		Id:       uuid.NewString(),
+		Name:     "response",
+		MimeType: mimeType,
+		Content:  &mesh.Artifact_Bytes{Bytes: []byte(text)},
+	})
+}
+
+// ensureSession creates the session unless an earlier task already did.
+func (s *ServerWrapper) ensureSession(ctx context.Context, key sessionKey) error {
+	_, err := s.sessions.Get(ctx, &session.GetRequest{
+		AppName:   s.agent.Name(),
+		UserID:    key.userID,
+		SessionID: key.sessionID,
+	})
+	if err == nil {
+		return nil
+	}
+	_, err = s.sessions.Create(ctx, &session.CreateRequest{
+		AppName:   s.agent.Name(),
+		UserID:    key.userID,
+		SessionID: key.sessionID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	return nil
+}
+
+// acquire marks key as in use by a task until the matching release.
+func (s *ServerWrapper) acquire(key sessionKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active == nil {
+		s.active = make(map[sessionKey]int)
+	}
+	s.active[key]++
+}
+
+func (s *ServerWrapper) release(key sessionKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active[key]--; s.active[key] <= 0 {
+		delete(s.active, key)
+	}
+}
+
+// Run expires idle sessions until ctx is done, sweeping once per session TTL or
+// minute, whichever is shorter. AgentServer runs it for every hosted agent; call it
+// when serving a ServerWrapper yourself.
+func (s *ServerWrapper) Run(ctx context.Context) {
+	if s.sessionTTL <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(min(s.sessionTTL, time.Minute))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.expireSessions(ctx)
+		}
+	}
+}
+
+// expireSessions deletes the agent's sessions that were not updated within the session
+// TTL and run no task, together with the tasks in them still waiting for input. Tasks
+// waiting for longer than the TTL are dropped too, even if other tasks keep their
+// session alive.
+func (s *ServerWrapper) expireSessions(ctx context.Context) {
+	resp, err := s.sessions.List(ctx, &session.ListRequest{AppName: s.agent.Name()})
+	if err != nil {
+		log.Printf("Failed to list sessions: %v", err)
+		return
+	}
+
+	cutoff := time.Now().Add(-s.sessionTTL)
+	s.mu.Lock()
+	var expired []sessionKey
+	for _, sess := range resp.Sessions {
+		key := sessionKey{userID: sess.UserID(), sessionID: sess.ID()}
+		if s.active[key] == 0 && sess.LastUpdateTime().Before(cutoff) {
+			expired = append(expired, key)
+		}
+	}
+	for taskID, p := range s.paused {
+		if slices.Contains(expired, p.session) || p.pausedAt.Before(cutoff) {
+			delete(s.paused, taskID)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, key := range expired {
+		err := s.sessions.Delete(ctx, &session.DeleteRequest{
+			AppName:   s.agent.Name(),
+			UserID:    key.userID,
+			SessionID: key.sessionID,
+		})
+		if err != nil {
+			log.Printf("Failed to expire session %s: %v", key.sessionID, err)
+		}
+	}
+	if len(expired) > 0 {
+		log.Printf("Expired %d sessions", len(expired))
+	}
+}
+
+func (s *ServerWrapper) pause(taskID string, p pausedTask) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.paused == nil {
+		s.paused = make(map[string]pausedTask)
+	}
+	p.pausedAt = time.Now()
+	s.paused[taskID] = p
+}
+
+// resume removes taskID from the paused tasks and reports whether it was there.
+// Only the caller that started a task can resume it.
+func (s *ServerWrapper) resume(taskID, userID string) (pausedTask, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.paused[taskID]
+	if !ok || p.session.userID != userID {
+		return pausedTask{}, false
+	}
+	delete(s.paused, taskID)
+	return p, true
+}
+
+// hasTask reports whether taskID is running or waiting for input.
+func (s *ServerWrapper) hasTask(taskID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, running := s.running[taskID]
+	_, paused := s.paused[taskID]
+	return running || paused
+}
+
+func (s *ServerWrapper) track(taskID string, cancel context.CancelFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running == nil {
+		s.running = make(map[string]context.CancelFunc)
+	}
+	s.running[taskID] = cancel
+}
+
+func (s *ServerWrapper) untrack(taskID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.running, taskID)
+}
+
+// sendStatus sends a status update for taskID on stream.
+func sendStatus(stream mesh.A2AMeshService_StreamTaskServer, taskID string, status mesh.Task_Status, message string) error {
+	return stream.Send(&mesh.StreamEvent{
+		Event: &mesh.StreamEvent_StatusUpdate{
+			StatusUpdate: &mesh.TaskStatusUpdate{
+				TaskId:  taskID,
+				Status:  status,
+				Message: message,
+			},
+		},
+	})
+}
+
+// callerID returns the identity of the calling agent, which the sidecar passes in the
+// x-caller-id metadata, or defaultUserID.
+func callerID(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if ids := md.Get("x-caller-id"); len(ids) > 0 && ids[0] != "" {
+		return ids[0]
+	}
+	return defaultUserID
+}
+
+// inputRequest returns the long-running tool call in evt, if any.
+func inputRequest(evt *session.Event) *genai.FunctionCall {
+	if len(evt.LongRunningToolIDs) == 0 || evt.LLMResponse.Content == nil {
+		return nil
+	}
+	for _, part := range evt.LLMResponse.Content.Parts {
+		if part.FunctionCall != nil && slices.Contains(evt.LongRunningToolIDs, part.FunctionCall.ID) {
+			return part.FunctionCall
+		}
+	}
+	return nil
+}
+
+// question is the message shown to the caller for a pending tool call: its "question"
+// argument when it has one, the tool name otherwise.
+func question(call *genai.FunctionCall) string {
+	if q, ok := call.Args["question"].(string); ok && q != "" {
+		return q
+	}
+	return call.Name
+}
+
+// sendFailure sends a FAILED status update for taskID describing err.
+func sendFailure(stream mesh.A2AMeshService_StreamTaskServer, taskID string, err error) error {
+	return stream.Send(&mesh.StreamEvent{
+		Event: &mesh.StreamEvent_StatusUpdate{
+			StatusUpdate: &mesh.TaskStatusUpdate{
+				TaskId:  taskID,
+				Status:  mesh.Task_FAILED,
+				Message: err.Error(),
+				Error:   toTaskError(err),
+			},
+		},
+	})
+}
+
+// sendToolEvent sends a WORKING status update for taskID describing a tool call or result.
+// Values that do not fit a Struct are dropped from data rather than failing the task.
+func sendToolEvent(stream mesh.A2AMeshService_StreamTaskServer, taskID, message string, data map[string]any) error {
+	update := &mesh.TaskStatusUpdate{
+		TaskId:  taskID,
+		Status:  mesh.Task_WORKING,
+		Message: message,
+	}
+	if st, err := toStruct(data); err != nil {
+		log.Printf("Failed to encode tool event for task %s: %v", taskID, err)
+	} else {
+		update.Data = st
+	}
+	return stream.Send(&mesh.StreamEvent{
+		Event: &mesh.StreamEvent_StatusUpdate{StatusUpdate: update},
+	})
+}
+
+// sendArtifact sends artifact for taskID on stream.
+func sendArtifact(stream mesh.A2AMeshService_StreamTaskServer, taskID string, artifact *mesh.Artifact) error {
+	return stream.Send(&mesh.StreamEvent{
+		Event: &mesh.StreamEvent_ArtifactUpdate{
+			ArtifactUpdate: &mesh.TaskArtifactUpdate{
+				TaskId:   taskID,
+				Artifact: artifact,
+			},
+		},
+	})
+}
@@ -0,0 +1,159 @@
+package adk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+	registry "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/registry"
+	"github.com/google/jsonschema-go/jsonschema"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// SkillSchemaExtension is the URI of the AgentCard extension declaring JSON Schemas
+// for the agent's skills. Its params map skill IDs to their schemas:
+//
+//	{"skills": {"<skill id>": {"inputSchema": {...}, "outputSchema": {...}}}}
+const SkillSchemaExtension = "https://agentmesh.dev/extensions/skill-schema/v1"
+
+// invalidToolName matches the characters not allowed in a function name.
+var invalidToolName = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+// SkillTool creates a tool for skill of the remote agent described by card. When the
+// card declares an input schema for the skill through SkillSchemaExtension, the tool takes
+// those arguments and sends them as a DataPart, and a JSON response from the remote agent
+// is returned as the tool result, validated against the output schema if there is one.
+// Skills without a schema get a RemoteTool. Calls go to the agent with the card's DID.
+func SkillTool(card *registry.AgentCard, skill *registry.AgentSkill) (tool.Tool, error) {
+	return skillTool(toolName(skill), card.Did, card, skill)
+}
+
+// skillTool is SkillTool for the agent registered as agentID, named name.
+func skillTool(name, agentID string, card *registry.AgentCard, skill *registry.AgentSkill) (tool.Tool, error) {
+	description := skill.Description
+	if description == "" {
+		description = skill.Name
+	}
+
+	input, output, err := skillSchemas(card, skill.Id)
+	if err != nil {
+		return nil, fmt.Errorf("skill %s: %w", skill.Id, err)
+	}
+	if input == nil {
+		return RemoteToolWithConfig(RemoteToolConfig{Name: name, Description: description, Skill: skill.Id, AgentID: agentID})
+	}
+
+	var resolvedOutput *jsonschema.Resolved
+	if output != nil {
+		if resolvedOutput, err = output.Resolve(nil); err != nil {
+			return nil, fmt.Errorf("skill %s: invalid output schema: %w", skill.Id, err)
+		}
+	}
+
+	remote := newRemoteCaller(name, agentID, skill.Id)
+	handler := func(toolCtx tool.Context, args map[string]any) (map[string]any, error) {
+		data, err := structpb.NewStruct(args)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert arguments to struct: %w", err)
+		}
+
+		res := remote.call(toolCtx, "", []*mesh.Part{{Content: &mesh.Part_DataPart{DataPart: data}}})
+		if res.err != nil || res.status != "completed" {
+			return res.toolResult(), nil
+		}
+		if resolvedOutput == nil {
+			if res.data == nil {
+				return res.toolResult(), nil
+			}
+			return res.data, nil
+		}
+
+		// A skill with an output schema must answer with structured output.
+		invalid := errors.New("the response is not a JSON object")
+		if res.data != nil {
+			invalid = resolvedOutput.Validate(res.data)
+		}
+		if invalid != nil {
+			res.status = "failed"
+			res.err = &TaskError{
+				TaskID:  res.taskID,
+				Code:    codes.Internal.String(),
+				Message: fmt.Sprintf("output does not match the schema of skill %s: %v", skill.Id, invalid),
+			}
+			return res.toolResult(), nil
+		}
+		return res.data, nil
+	}
+
+	return functiontool.New(functiontool.Config{
+		Name:        name,
+		Description: description,
+		InputSchema: input,
+	}, handler)
+}
+
+// toolName derives a valid function name from the skill's ID, or its name without one.
+func toolName(skill *registry.AgentSkill) string {
+	name := skill.Id
+	if name == "" {
+		name = skill.Name
+	}
+	return validToolName(name)
+}
+
+// qualifiedToolName is toolName prefixed with the name of the agent offering the skill.
+func qualifiedToolName(card *registry.AgentCard, skill *registry.AgentSkill) string {
+	return validToolName(card.GetName() + "." + toolName(skill))
+}
+
+// validToolName replaces the characters not allowed in a function name and truncates it.
+func validToolName(name string) string {
+	name = invalidToolName.ReplaceAllString(name, "_")
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
+
+// skillSchemas returns the input and output schemas declared for skillID in card's
+// SkillSchemaExtension, or nil when there are none.
+func skillSchemas(card *registry.AgentCard, skillID string) (input, output *jsonschema.Schema, err error) {
+	for _, ext := range card.GetCapabilities().GetExtensions() {
+		if ext.Uri != SkillSchemaExtension {
+			continue
+		}
+		skills := ext.GetParams().GetFields()["skills"].GetStructValue()
+		schemas := skills.GetFields()[skillID].GetStructValue()
+		if schemas == nil {
+			return nil, nil, nil
+		}
+		if input, err = toSchema(schemas.GetFields()["inputSchema"]); err != nil {
+			return nil, nil, fmt.Errorf("invalid input schema: %w", err)
+		}
+		if output, err = toSchema(schemas.GetFields()["outputSchema"]); err != nil {
+			return nil, nil, fmt.Errorf("invalid output schema: %w", err)
+		}
+		return input, output, nil
+	}
+	return nil, nil, nil
+}
+
+func toSchema(v *structpb.Value) (*jsonschema.Schema, error) {
+	if v.GetStructValue() == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v.GetStructValue().AsMap())
+	if err != nil {
+		return nil, err
+	}
+	schema := &jsonschema.Schema{}
+	if err := json.Unmarshal(data, schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
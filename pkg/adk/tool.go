@@ -1,30 +1,81 @@
 package adk
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"os"
+	"strings"
+	"time"
 
 	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/lifecycle"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/telemetry"
 	"github.com/google/jsonschema-go/jsonschema"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/adk/session"
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/functiontool"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/genai"
+	grpccodes "google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
+// RemoteToolConfig configures a tool proxying calls to a remote agent.
+type RemoteToolConfig struct {
+	Name        string
+	Description string
+	// Skill is the skill of the remote agent to call.
+	Skill string
+	// AgentID pins the remote agent. Without it, the Sidecar picks an agent for Skill.
+	AgentID string
+	// Timeout bounds each call. When it expires the remote task is canceled and the
+	// result fails with DeadlineExceeded. Zero means no timeout.
+	Timeout time.Duration
+	// MaxOutputBytes bounds the result text of the remote task. Larger output cancels
+	// the task, and the result fails with ResourceExhausted and holds the truncated
+	// text. Zero means no limit.
+	MaxOutputBytes int
+	// Client is the connection to the local Sidecar. Defaults to one shared by all
+	// remote tools and agents, at the port in AGENTMESH_SIDECAR_PORT.
+	Client *MeshClient
+	// OnProgress, when set, is called with the remote task's status changes, messages
+	// and artifacts while the call runs, as partial events of the calling agent. ADK tools
+	// cannot emit events, so these do not reach the runner: the application forwards
+	// them itself, e.g. to its UI. A NewRemoteAgent streams them through the runner.
+	OnProgress func(tool.Context, *session.Event)
+}
+
 // RemoteTool creates a new tool that proxies calls to the specified remote agent.
 // It replicates the behavior of agenttool by using a default "request" string parameter.
+// When the remote agent asks for input, the result carries its question and the task_id;
+// calling the tool again with that task_id and the answer as request continues the task.
+// When the task fails, the result has status "failed", "canceled" or "rejected" and a
+// TaskError, i.e. its code, message and retryable flag, in its "error" field.
 func RemoteTool(name, description, targetSkill string) (tool.Tool, error) {
-	port := os.Getenv("AGENTMESH_SIDECAR_PORT")
-	if port == "" {
-		port = "50052" // Default sidecar local port
+	return RemoteToolWithConfig(RemoteToolConfig{Name: name, Description: description, Skill: targetSkill})
+}
+
+// RemoteToolWithConfig creates a RemoteTool as configured by cfg.
+func RemoteToolWithConfig(cfg RemoteToolConfig) (tool.Tool, error) {
+	name := cfg.Name
+	remote := newRemoteCaller(name, cfg.AgentID, cfg.Skill)
+	remote.timeout = cfg.Timeout
+	remote.maxOutput = cfg.MaxOutputBytes
+	remote.onProgress = cfg.OnProgress
+	if cfg.Client != nil {
+		remote.client = cfg.Client
 	}
 
 	// Handler function that executes the tool logic
-	handler := func(ctx tool.Context, input map[string]any) (map[string]any, error) {
+	handler := func(toolCtx tool.Context, input map[string]any) (map[string]any, error) {
+		// A task_id continues a remote task that is waiting for input.
+		taskID, _ := input["task_id"].(string)
+
 		// Extract 'request' from input, mirroring agenttool behavior
 		req, ok := input["request"].(string)
 		if !ok {
@@ -42,97 +93,26 @@ func RemoteTool(name, description, targetSkill string) (tool.Tool, error) {
 			return nil, fmt.Errorf("missing string argument 'request' for tool %s", name)
 		}
 
-		// Connect to the local Sidecar
-		conn, err := grpc.NewClient(fmt.Sprintf("127.0.0.1:%s", port), grpc.WithTransportCredentials(insecure.NewCredentials()))
-		if err != nil {
-			return nil, fmt.Errorf("failed to connect to local sidecar: %w", err)
+		// We send the 'req' (instruction) as the TextPart of the message. Any arguments
+		// besides the request travel as a DataPart, which the remote agent receives as JSON.
+		parts := []*mesh.Part{
+			{Content: &mesh.Part_TextPart{TextPart: req}},
 		}
-		defer conn.Close()
-
-		client := mesh.NewA2AMeshServiceClient(conn)
-
-		// Convert input to Protobuf Struct.
-		// Note: We are wrapping the original input map to preserve full context if needed,
-		// but primarily sending the text 'req' as the prompt message.
-		// However, the mesh protocol expects structured input (DataPart) or text (TextPart).
-		// Let's iterate on this: Send 'req' as the user message text.
-
-		inputStruct, err := structpb.NewStruct(input)
-		if err != nil {
-			return nil, fmt.Errorf("failed to convert input to struct: %w", err)
-		}
-
-		// Prepare Metadata for Routing
-		md := metadata.Pairs("x-target-skill", targetSkill)
-		outCtx := metadata.NewOutgoingContext(ctx, md)
-
-		// Start Stream
-		stream, err := client.StreamTask(outCtx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to start stream: %w", err)
-		}
-
-		// Construct and Send TaskStart
-		// We send the 'req' (instruction) as the TextPart of the message.
-		reqMsg := &mesh.TaskSendRequest{
-			TargetAgentId: targetSkill,
-			Message: &mesh.Message{
-				Role: "user",
-				Parts: []*mesh.Part{
-					{
-						Content: &mesh.Part_TextPart{
-							TextPart: req,
-						},
-					},
-					// We also include the full raw input as DataPart just in case the remote agent needs other fields.
-					{
-						Content: &mesh.Part_DataPart{
-							DataPart: inputStruct,
-						},
-					},
-				},
-			},
-		}
-
-		err = stream.Send(&mesh.StreamEvent{
-			Event: &mesh.StreamEvent_TaskStart{
-				TaskStart: &mesh.TaskStart{
-					Request: reqMsg,
-				},
-			},
-		})
-		if err != nil {
-			return nil, fmt.Errorf("failed to send task start: %w", err)
-		}
-
-		// Close send direction to indicate we are done sending
-		stream.CloseSend()
-
-		// Receive Response
-		var outputText string
-		for {
-			event, err := stream.Recv()
-			if err == io.EOF {
-				break
+		extra := make(map[string]any)
+		for k, v := range input {
+			if k != "request" && k != "task_id" {
+				extra[k] = v
 			}
+		}
+		if len(extra) > 0 {
+			inputStruct, err := structpb.NewStruct(extra)
 			if err != nil {
-				return nil, fmt.Errorf("stream recv error: %w", err)
-			}
-
-			if status := event.GetStatusUpdate(); status != nil {
-				if status.Message != "" {
-					outputText += status.Message + "\n"
-				}
-				if status.Status == mesh.Task_COMPLETED || status.Status == mesh.Task_FAILED {
-					break
-				}
-			}
-			if art := event.GetArtifactUpdate(); art != nil {
-				outputText += fmt.Sprintf("[Artifact: %s]\n", art.Artifact.Name)
+				return nil, fmt.Errorf("failed to convert input to struct: %w", err)
 			}
+			parts = append(parts, &mesh.Part{Content: &mesh.Part_DataPart{DataPart: inputStruct}})
 		}
 
-		return map[string]any{"result": outputText}, nil
+		return remote.call(toolCtx, taskID, parts).toolResult(), nil
 	}
 
 	// Replicate agenttool's default schema: {"request": "string"}
@@ -143,13 +123,279 @@ func RemoteTool(name, description, targetSkill string) (tool.Tool, error) {
 				Type:        "string",
 				Description: "The request or instruction for the agent.",
 			},
+			"task_id": {
+				Type:        "string",
+				Description: "Set to the task_id of a previous result with status input_required to continue that task; request is then the answer to its question.",
+			},
 		},
 		Required: []string{"request"},
 	}
 
 	return functiontool.New(functiontool.Config{
 		Name:        name,
-		Description: description,
+		Description: cfg.Description,
 		InputSchema: defaultSchema,
 	}, handler)
 }
+
+// remoteCaller runs tasks on a remote agent through the local Sidecar.
+type remoteCaller struct {
+	name string
+	// agentID pins the remote agent; without it the Sidecar picks one for targetSkill.
+	agentID     string
+	targetSkill string
+	client      *MeshClient
+	timeout     time.Duration
+	maxOutput   int
+	onProgress  func(tool.Context, *session.Event)
+}
+
+func newRemoteCaller(name, agentID, targetSkill string) *remoteCaller {
+	return &remoteCaller{name: name, agentID: agentID, targetSkill: targetSkill, client: sidecarClient()}
+}
+
+// remoteResult is the outcome of a remote task as seen by the calling tool.
+type remoteResult struct {
+	taskID string
+	// status is "completed", "input_required", "failed", "canceled" or "rejected".
+	status string
+	// text aggregates the streamed messages and the artifacts other than the response.
+	// It is the result only when no response arrives.
+	text string
+	// response is the text of the "response" artifacts, the remote agent's answer.
+	response    string
+	hasResponse bool
+	// data is the structured output: the JSON response of the remote agent, if any.
+	data map[string]any
+	// question is what the remote agent asked, when its status is input_required.
+	question string
+	err      *TaskError
+}
+
+// output is the result text: the response, or what was streamed without one.
+func (r *remoteResult) output() string {
+	if r.hasResponse {
+		return r.response
+	}
+	return r.text
+}
+
+// toolResult is the result reported to the calling agent. Structured output is
+// included under "data" whatever the status.
+func (r *remoteResult) toolResult() map[string]any {
+	result := map[string]any{"result": r.output()}
+	switch {
+	case r.err != nil:
+		result["status"] = r.status
+		result["task_id"] = r.taskID
+		result["error"] = r.err
+	case r.status == "input_required":
+		// Surface the question to the calling LLM, which answers it in a further call.
+		result["status"] = r.status
+		result["question"] = r.question
+		result["task_id"] = r.taskID
+	}
+	if r.data != nil {
+		result["data"] = r.data
+	}
+	return result
+}
+
+// call sends parts to the remote agent, continuing taskID if set, and follows the task
+// until it completes, fails or asks for input. Failures are reported in the result,
+// since ADK passes tool errors to the model as an opaque string.
+func (c *remoteCaller) call(toolCtx tool.Context, taskID string, parts []*mesh.Part) (res *remoteResult) {
+	ctx, span := telemetry.Tracer().Start(toolCtx, "RemoteTool "+c.name, trace.WithSpanKind(trace.SpanKindClient))
+	span.SetAttributes(attribute.String("agentmesh.target_skill", c.targetSkill))
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	res = &remoteResult{taskID: taskID, status: "completed"}
+	defer func() {
+		if res.err != nil {
+			span.RecordError(res.err)
+			span.SetStatus(codes.Error, res.err.Error())
+		}
+		span.End()
+	}()
+
+	fail := func(update *mesh.TaskStatusUpdate, cause error) *remoteResult {
+		res.err = remoteError(res.taskID, update, cause)
+		res.status = "failed"
+		if update != nil && update.Status != mesh.Task_FAILED {
+			res.status = strings.ToLower(update.Status.String())
+		}
+		return res
+	}
+
+	stream, done, err := c.start(ctx, &mesh.TaskSendRequest{
+		TaskId:  taskID,
+		Message: &mesh.Message{Role: "user", Parts: parts},
+	})
+	if err != nil {
+		return fail(nil, err)
+	}
+	defer done()
+
+	// Receive Response
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return res
+		}
+		if err != nil {
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				err = fmt.Errorf("remote task timed out after %s: %w", c.timeout, context.DeadlineExceeded)
+			}
+			return fail(nil, err)
+		}
+
+		status := event.GetStatusUpdate()
+		if status != nil {
+			res.taskID = status.TaskId
+		}
+		c.progress(toolCtx, res.taskID, event)
+
+		if status != nil {
+			if status.Status == mesh.Task_INPUT_REQUIRED {
+				res.status = "input_required"
+				res.question = status.Message
+				return res
+			}
+			if lifecycle.IsTerminal(status.Status) && status.Status != mesh.Task_COMPLETED {
+				return fail(status, nil)
+			}
+			if status.Message != "" {
+				res.text += status.Message + "\n"
+			}
+		}
+		if art := event.GetArtifactUpdate(); art != nil {
+			res.addArtifact(art.Artifact)
+		}
+
+		// Returning closes the stream, which cancels the remote task.
+		if output := res.output(); c.maxOutput > 0 && len(output) > c.maxOutput {
+			res.text = strings.ToValidUTF8(output[:c.maxOutput], "")
+			res.response, res.hasResponse = "", false
+			res.status = "failed"
+			res.err = &TaskError{
+				TaskID:  res.taskID,
+				Code:    grpccodes.ResourceExhausted.String(),
+				Message: fmt.Sprintf("output of remote task exceeds %d bytes", c.maxOutput),
+			}
+			return res
+		}
+		if lifecycle.IsTerminal(status.GetStatus()) {
+			return res
+		}
+	}
+}
+
+// progress passes event of the remote task to OnProgress as a partial event of the
+// calling agent. Its custom metadata names the tool, the function call and the task.
+func (c *remoteCaller) progress(toolCtx tool.Context, taskID string, event *mesh.StreamEvent) {
+	if c.onProgress == nil {
+		return
+	}
+	evt := session.NewEvent(toolCtx.InvocationID())
+	evt.Author = toolCtx.AgentName()
+	evt.Branch = toolCtx.Branch()
+	describeProgress(evt, taskID, event)
+	evt.LLMResponse.CustomMetadata["agentmesh:tool"] = c.name
+	evt.LLMResponse.CustomMetadata["agentmesh:function_call_id"] = toolCtx.FunctionCallID()
+	c.onProgress(toolCtx, evt)
+}
+
+// describeProgress makes evt a partial event reporting event of the remote task taskID:
+// a status update's status, message and data, or an artifact. Its custom metadata
+// holds the task ID and the status or artifact name.
+func describeProgress(evt *session.Event, taskID string, event *mesh.StreamEvent) {
+	evt.LLMResponse.Partial = true
+	evt.LLMResponse.CustomMetadata = map[string]any{"agentmesh:task_id": taskID}
+
+	if update := event.GetStatusUpdate(); update != nil {
+		evt.LLMResponse.CustomMetadata["agentmesh:status"] = update.Status.String()
+		if update.Data != nil {
+			evt.LLMResponse.CustomMetadata["agentmesh:data"] = update.Data.AsMap()
+		}
+		if update.Message != "" {
+			evt.LLMResponse.Content = genai.NewContentFromText(update.Message, genai.RoleModel)
+		}
+	}
+	if art := event.GetArtifactUpdate().GetArtifact(); art != nil {
+		evt.LLMResponse.CustomMetadata["agentmesh:artifact"] = art.Name
+		if part := fromArtifact(art); part != nil {
+			evt.LLMResponse.Content = &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{part}}
+		}
+	}
+}
+
+// start sends req to the remote agent through the local Sidecar and returns the stream
+// of the task's events. done closes the stream, which cancels a task still running.
+func (c *remoteCaller) start(ctx context.Context, req *mesh.TaskSendRequest) (stream mesh.A2AMeshService_StreamTaskClient, done func(), err error) {
+	client, err := c.client.Client()
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		if err != nil {
+			cancel()
+		}
+	}()
+
+	// Prepare Metadata for Routing
+	md := metadata.Pairs("x-target-skill", c.targetSkill)
+	req.TargetAgentId = c.targetSkill
+	if c.agentID != "" {
+		md.Set("x-target-agent", c.agentID)
+		req.TargetAgentId = c.agentID
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("agentmesh.remote_agent", c.agentID))
+	}
+	outCtx := metadata.NewOutgoingContext(ctx, md)
+
+	// Start Stream
+	stream, err = client.StreamTask(outCtx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Construct and Send TaskStart
+	err = stream.Send(&mesh.StreamEvent{
+		Event: &mesh.StreamEvent_TaskStart{TaskStart: &mesh.TaskStart{Request: req}},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Close send direction to indicate we are done sending
+	if err = stream.CloseSend(); err != nil {
+		return nil, nil, err
+	}
+	return stream, cancel, nil
+}
+
+// addArtifact adds artifact to the result. The "response" artifact is the remote
+// agent's answer and becomes the result text, without the messages streamed before
+// it; a JSON response is also the structured output. Other text artifacts are passed
+// on verbatim.
+func (r *remoteResult) addArtifact(artifact *mesh.Artifact) {
+	data := artifact.GetBytes()
+	switch {
+	case artifact.Name == "response" && data != nil:
+		if artifact.MimeType == jsonMIMEType {
+			var output map[string]any
+			if err := json.Unmarshal(data, &output); err == nil {
+				r.data = output
+			}
+		}
+		r.response += string(data) + "\n"
+		r.hasResponse = true
+	case (artifact.MimeType == jsonMIMEType || strings.HasPrefix(artifact.MimeType, "text/")) && data != nil:
+		r.text += string(data) + "\n"
+	default:
+		r.text += fmt.Sprintf("[Artifact: %s]\n", artifact.Name)
+	}
+}
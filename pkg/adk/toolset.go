@@ -0,0 +1,216 @@
+package adk
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	registry "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/registry"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/telemetry"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/tool"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	defaultToolsetName     = "agentmesh"
+	defaultRefreshInterval = 30 * time.Second
+	// listPageSize is the number of registry entries fetched per ListAgents call.
+	listPageSize = 50
+)
+
+// RegistryToolsetConfig selects the remote agents offered by a RegistryToolset.
+type RegistryToolsetConfig struct {
+	// Name is the name of the toolset. Defaults to "agentmesh".
+	Name string
+	// Registry is the client used to query the registry. Without it, the toolset
+	// connects to RegistryAddr.
+	Registry registry.RegistryServiceClient
+	// RegistryAddr is the address of the registry. Defaults to AGENTMESH_REGISTRY_URL,
+	// then localhost:50051.
+	RegistryAddr string
+	// Credentials secure the connection to RegistryAddr, e.g. TLS for a registry
+	// serving over TLS. Defaults to plaintext.
+	Credentials credentials.TransportCredentials
+	// Tags keeps the agents carrying at least one of the tags.
+	Tags []string
+	// Namespace keeps the agents registered with this "namespace" metadata value.
+	Namespace string
+	// Skill keeps only the skills with this ID or name (case-insensitive).
+	Skill string
+	// RefreshInterval is how long the discovered tools are used before the registry is
+	// queried again. Defaults to 30s.
+	RefreshInterval time.Duration
+}
+
+// RegistryToolset is an ADK toolset with one tool per skill of the remote agents in
+// the registry. Tools are built with SkillTool, so they are named after the skill ID
+// and described by the skill, and each tool calls the agent that offers the skill.
+// When two agents offer a skill with the same ID, the tool of the most recently
+// updated one keeps the skill's name, and the others are named "<agent>.<skill>".
+type RegistryToolset struct {
+	name     string
+	registry registry.RegistryServiceClient
+	conn     *grpc.ClientConn
+	cfg      RegistryToolsetConfig
+
+	// mu guards the discovered tools. It is not held while querying the registry;
+	// refreshing is set meanwhile, so that other callers keep using the tools they have.
+	mu          sync.Mutex
+	tools       []tool.Tool
+	refreshedAt time.Time
+	refreshing  bool
+}
+
+// NewRegistryToolset creates a toolset discovering remote agents as configured by cfg.
+// The registry is first queried when the agent asks for its tools.
+func NewRegistryToolset(cfg RegistryToolsetConfig) (*RegistryToolset, error) {
+	if cfg.Name == "" {
+		cfg.Name = defaultToolsetName
+	}
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = defaultRefreshInterval
+	}
+
+	ts := &RegistryToolset{name: cfg.Name, registry: cfg.Registry, cfg: cfg}
+	if ts.registry == nil {
+		addr := cfg.RegistryAddr
+		if addr == "" {
+			addr = os.Getenv("AGENTMESH_REGISTRY_URL")
+		}
+		if addr == "" {
+			addr = "localhost:50051"
+		}
+		creds := cfg.Credentials
+		if creds == nil {
+			creds = insecure.NewCredentials()
+		}
+		conn, err := grpc.NewClient(addr,
+			grpc.WithTransportCredentials(creds),
+			telemetry.DialOption(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to registry: %w", err)
+		}
+		ts.conn = conn
+		ts.registry = registry.NewRegistryServiceClient(conn)
+	}
+	return ts, nil
+}
+
+// Name implements tool.Toolset.
+func (ts *RegistryToolset) Name() string {
+	return ts.name
+}
+
+// Tools implements tool.Toolset. It queries the registry when the last result is
+// older than the refresh interval. If the registry cannot be reached, the tools
+// discovered before are kept, and they are used while another call refreshes them.
+func (ts *RegistryToolset) Tools(ctx agent.ReadonlyContext) ([]tool.Tool, error) {
+	ts.mu.Lock()
+	discovered := !ts.refreshedAt.IsZero()
+	if discovered && (ts.refreshing || time.Since(ts.refreshedAt) < ts.cfg.RefreshInterval) {
+		defer ts.mu.Unlock()
+		return ts.tools, nil
+	}
+	ts.refreshing = true
+	ts.mu.Unlock()
+
+	tools, err := ts.discover(ctx)
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.refreshing = false
+	if err != nil {
+		if ts.refreshedAt.IsZero() {
+			return nil, err
+		}
+		log.Printf("Failed to refresh toolset %s, keeping %d tools: %v", ts.name, len(ts.tools), err)
+		return ts.tools, nil
+	}
+	ts.tools = tools
+	ts.refreshedAt = time.Now()
+	return ts.tools, nil
+}
+
+// Close closes the registry connection opened by NewRegistryToolset.
+func (ts *RegistryToolset) Close() error {
+	if ts.conn == nil {
+		return nil
+	}
+	return ts.conn.Close()
+}
+
+// discover lists the matching agents and builds a tool for each of their skills.
+func (ts *RegistryToolset) discover(ctx context.Context) ([]tool.Tool, error) {
+	var (
+		tools []tool.Tool
+		names = make(map[string]bool)
+	)
+	for offset := 0; ; {
+		resp, err := ts.registry.ListAgents(ctx, &registry.ListAgentsRequest{
+			Limit:  listPageSize,
+			Offset: int32(offset),
+			Tags:   ts.cfg.Tags,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list agents: %w", err)
+		}
+
+		for _, entry := range resp.Agents {
+			if !ts.matches(entry) {
+				continue
+			}
+			for _, skill := range entry.AgentCard.GetSkills() {
+				if !ts.matchesSkill(skill) {
+					continue
+				}
+				name := toolName(skill)
+				if names[name] {
+					name = qualifiedToolName(entry.AgentCard, skill)
+				}
+				if names[name] {
+					log.Printf("Skipping skill %s of agent %s: a tool named %s already exists", skill.Id, entry.AgentId, name)
+					continue
+				}
+				t, err := skillTool(name, entry.AgentId, entry.AgentCard, skill)
+				if err != nil {
+					log.Printf("Skipping skill %s of agent %s: %v", skill.Id, entry.AgentId, err)
+					continue
+				}
+				names[name] = true
+				tools = append(tools, t)
+			}
+		}
+
+		offset += len(resp.Agents)
+		if len(resp.Agents) == 0 || offset >= int(resp.Total) {
+			return tools, nil
+		}
+	}
+}
+
+// matches reports whether entry is in the configured namespace.
+func (ts *RegistryToolset) matches(entry *registry.RegistryEntry) bool {
+	if ts.cfg.Namespace == "" {
+		return true
+	}
+	return entry.GetMetadata().GetFields()["namespace"].GetStringValue() == ts.cfg.Namespace
+}
+
+// matchesSkill reports whether skill passes the configured skill filter.
+func (ts *RegistryToolset) matchesSkill(skill *registry.AgentSkill) bool {
+	if ts.cfg.Skill == "" {
+		return true
+	}
+	return slices.ContainsFunc([]string{skill.Id, skill.Name}, func(s string) bool {
+		return strings.EqualFold(s, ts.cfg.Skill)
+	})
+}
@@ -24,6 +24,9 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Status follows the A2A task lifecycle. COMPLETED, FAILED, CANCELED and
+// REJECTED are terminal; INPUT_REQUIRED and AUTH_REQUIRED pause the task
+// until the client sends another message.
 type Task_Status int32
 
 const (
@@ -32,6 +35,10 @@ const (
 	Task_WORKING            Task_Status = 2
 	Task_COMPLETED          Task_Status = 3
 	Task_FAILED             Task_Status = 4
+	Task_INPUT_REQUIRED     Task_Status = 5
+	Task_CANCELED           Task_Status = 6
+	Task_REJECTED           Task_Status = 7
+	Task_AUTH_REQUIRED      Task_Status = 8
 )
 
 // Enum value maps for Task_Status.
@@ -42,6 +49,10 @@ var (
 		2: "WORKING",
 		3: "COMPLETED",
 		4: "FAILED",
+		5: "INPUT_REQUIRED",
+		6: "CANCELED",
+		7: "REJECTED",
+		8: "AUTH_REQUIRED",
 	}
 	Task_Status_value = map[string]int32{
 		"STATUS_UNSPECIFIED": 0,
@@ -49,6 +60,10 @@ var (
 		"WORKING":            2,
 		"COMPLETED":          3,
 		"FAILED":             4,
+		"INPUT_REQUIRED":     5,
+		"CANCELED":           6,
+		"REJECTED":           7,
+		"AUTH_REQUIRED":      8,
 	}
 )
 
@@ -76,10 +91,10 @@ func (x Task_Status) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use Task_Status.Descriptor instead.
 func (Task_Status) EnumDescriptor() ([]byte, []int) {
-	return file_pkg_api_v1_mesh_mesh_proto_rawDescGZIP(), []int{3, 0}
+	return file_pkg_api_v1_mesh_mesh_proto_rawDescGZIP(), []int{5, 0}
 }
 
-// TaskSendRequest initiates a new task.
+// TaskSendRequest initiates a new task, or continues one when task_id is set.
 type TaskSendRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Context ID for multi-turn conversations.
@@ -87,7 +102,9 @@ type TaskSendRequest struct {
 	// Target Agent ID for routing.
 	TargetAgentId string `protobuf:"bytes,2,opt,name=target_agent_id,json=targetAgentId,proto3" json:"target_agent_id,omitempty"`
 	// The initial message to start the task.
-	Message       *Message `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	Message *Message `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	// ID of an existing task to continue, e.g. to answer an INPUT_REQUIRED status.
+	TaskId        string `protobuf:"bytes,4,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -143,6 +160,13 @@ func (x *TaskSendRequest) GetMessage() *Message {
 	return nil
 }
 
+func (x *TaskSendRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
 // GetTaskRequest retrieves a task by ID.
 type GetTaskRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -233,21 +257,132 @@ func (x *CancelTaskRequest) GetId() string {
 	return ""
 }
 
+// ListTasksRequest filters the tasks known to the sidecar.
+type ListTasksRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only tasks belonging to this conversation.
+	ContextId string `protobuf:"bytes,1,opt,name=context_id,json=contextId,proto3" json:"context_id,omitempty"`
+	// Only tasks currently in this status.
+	Status Task_Status `protobuf:"varint,2,opt,name=status,proto3,enum=api.v1.mesh.Task_Status" json:"status,omitempty"`
+	// Maximum number of tasks to return; 0 returns all.
+	Limit         int32 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTasksRequest) Reset() {
+	*x = ListTasksRequest{}
+	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTasksRequest) ProtoMessage() {}
+
+func (x *ListTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTasksRequest.ProtoReflect.Descriptor instead.
+func (*ListTasksRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_api_v1_mesh_mesh_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListTasksRequest) GetContextId() string {
+	if x != nil {
+		return x.ContextId
+	}
+	return ""
+}
+
+func (x *ListTasksRequest) GetStatus() Task_Status {
+	if x != nil {
+		return x.Status
+	}
+	return Task_STATUS_UNSPECIFIED
+}
+
+func (x *ListTasksRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// ListTasksResponse holds the matching tasks.
+type ListTasksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tasks         []*Task                `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTasksResponse) Reset() {
+	*x = ListTasksResponse{}
+	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTasksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTasksResponse) ProtoMessage() {}
+
+func (x *ListTasksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTasksResponse.ProtoReflect.Descriptor instead.
+func (*ListTasksResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_api_v1_mesh_mesh_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListTasksResponse) GetTasks() []*Task {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
 // Task represents the central unit of work.
 type Task struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	SessionId     string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	Status        Task_Status            `protobuf:"varint,3,opt,name=status,proto3,enum=api.v1.mesh.Task_Status" json:"status,omitempty"`
-	History       []*Message             `protobuf:"bytes,4,rep,name=history,proto3" json:"history,omitempty"`
-	Artifacts     []*Artifact            `protobuf:"bytes,5,rep,name=artifacts,proto3" json:"artifacts,omitempty"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	SessionId string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Status    Task_Status            `protobuf:"varint,3,opt,name=status,proto3,enum=api.v1.mesh.Task_Status" json:"status,omitempty"`
+	History   []*Message             `protobuf:"bytes,4,rep,name=history,proto3" json:"history,omitempty"`
+	Artifacts []*Artifact            `protobuf:"bytes,5,rep,name=artifacts,proto3" json:"artifacts,omitempty"`
+	// Why the task failed, when it did.
+	Error         *TaskError `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Task) Reset() {
 	*x = Task{}
-	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[3]
+	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -259,7 +394,7 @@ func (x *Task) String() string {
 func (*Task) ProtoMessage() {}
 
 func (x *Task) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[3]
+	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -272,7 +407,7 @@ func (x *Task) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Task.ProtoReflect.Descriptor instead.
 func (*Task) Descriptor() ([]byte, []int) {
-	return file_pkg_api_v1_mesh_mesh_proto_rawDescGZIP(), []int{3}
+	return file_pkg_api_v1_mesh_mesh_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *Task) GetId() string {
@@ -310,6 +445,76 @@ func (x *Task) GetArtifacts() []*Artifact {
 	return nil
 }
 
+func (x *Task) GetError() *TaskError {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+// TaskError describes why a task failed.
+type TaskError struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Canonical gRPC code name, e.g. "Unavailable" or "InvalidArgument".
+	Code    string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// Whether sending the task again may succeed.
+	Retryable     bool `protobuf:"varint,3,opt,name=retryable,proto3" json:"retryable,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskError) Reset() {
+	*x = TaskError{}
+	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskError) ProtoMessage() {}
+
+func (x *TaskError) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskError.ProtoReflect.Descriptor instead.
+func (*TaskError) Descriptor() ([]byte, []int) {
+	return file_pkg_api_v1_mesh_mesh_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *TaskError) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *TaskError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *TaskError) GetRetryable() bool {
+	if x != nil {
+		return x.Retryable
+	}
+	return false
+}
+
 // Message represents a communication turn.
 type Message struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -322,7 +527,7 @@ type Message struct {
 
 func (x *Message) Reset() {
 	*x = Message{}
-	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[4]
+	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -334,7 +539,7 @@ func (x *Message) String() string {
 func (*Message) ProtoMessage() {}
 
 func (x *Message) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[4]
+	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -347,7 +552,7 @@ func (x *Message) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Message.ProtoReflect.Descriptor instead.
 func (*Message) Descriptor() ([]byte, []int) {
-	return file_pkg_api_v1_mesh_mesh_proto_rawDescGZIP(), []int{4}
+	return file_pkg_api_v1_mesh_mesh_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *Message) GetRole() string {
@@ -386,7 +591,7 @@ type Part struct {
 
 func (x *Part) Reset() {
 	*x = Part{}
-	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[5]
+	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -398,7 +603,7 @@ func (x *Part) String() string {
 func (*Part) ProtoMessage() {}
 
 func (x *Part) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[5]
+	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -411,7 +616,7 @@ func (x *Part) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Part.ProtoReflect.Descriptor instead.
 func (*Part) Descriptor() ([]byte, []int) {
-	return file_pkg_api_v1_mesh_mesh_proto_rawDescGZIP(), []int{5}
+	return file_pkg_api_v1_mesh_mesh_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *Part) GetContent() isPart_Content {
@@ -485,7 +690,7 @@ type FilePart struct {
 
 func (x *FilePart) Reset() {
 	*x = FilePart{}
-	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[6]
+	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -497,7 +702,7 @@ func (x *FilePart) String() string {
 func (*FilePart) ProtoMessage() {}
 
 func (x *FilePart) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[6]
+	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -510,7 +715,7 @@ func (x *FilePart) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FilePart.ProtoReflect.Descriptor instead.
 func (*FilePart) Descriptor() ([]byte, []int) {
-	return file_pkg_api_v1_mesh_mesh_proto_rawDescGZIP(), []int{6}
+	return file_pkg_api_v1_mesh_mesh_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *FilePart) GetData() isFilePart_Data {
@@ -578,7 +783,7 @@ type Artifact struct {
 
 func (x *Artifact) Reset() {
 	*x = Artifact{}
-	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[7]
+	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -590,7 +795,7 @@ func (x *Artifact) String() string {
 func (*Artifact) ProtoMessage() {}
 
 func (x *Artifact) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[7]
+	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -603,7 +808,7 @@ func (x *Artifact) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Artifact.ProtoReflect.Descriptor instead.
 func (*Artifact) Descriptor() ([]byte, []int) {
-	return file_pkg_api_v1_mesh_mesh_proto_rawDescGZIP(), []int{7}
+	return file_pkg_api_v1_mesh_mesh_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *Artifact) GetId() string {
@@ -683,7 +888,7 @@ type StreamEvent struct {
 
 func (x *StreamEvent) Reset() {
 	*x = StreamEvent{}
-	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[8]
+	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -695,7 +900,7 @@ func (x *StreamEvent) String() string {
 func (*StreamEvent) ProtoMessage() {}
 
 func (x *StreamEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[8]
+	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -708,7 +913,7 @@ func (x *StreamEvent) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StreamEvent.ProtoReflect.Descriptor instead.
 func (*StreamEvent) Descriptor() ([]byte, []int) {
-	return file_pkg_api_v1_mesh_mesh_proto_rawDescGZIP(), []int{8}
+	return file_pkg_api_v1_mesh_mesh_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *StreamEvent) GetEvent() isStreamEvent_Event {
@@ -777,7 +982,7 @@ type TaskStart struct {
 
 func (x *TaskStart) Reset() {
 	*x = TaskStart{}
-	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[9]
+	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -789,7 +994,7 @@ func (x *TaskStart) String() string {
 func (*TaskStart) ProtoMessage() {}
 
 func (x *TaskStart) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[9]
+	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -802,7 +1007,7 @@ func (x *TaskStart) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TaskStart.ProtoReflect.Descriptor instead.
 func (*TaskStart) Descriptor() ([]byte, []int) {
-	return file_pkg_api_v1_mesh_mesh_proto_rawDescGZIP(), []int{9}
+	return file_pkg_api_v1_mesh_mesh_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *TaskStart) GetRequest() *TaskSendRequest {
@@ -814,17 +1019,21 @@ func (x *TaskStart) GetRequest() *TaskSendRequest {
 
 // TaskStatusUpdate represents a change in task status.
 type TaskStatusUpdate struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
-	Status        Task_Status            `protobuf:"varint,2,opt,name=status,proto3,enum=api.v1.mesh.Task_Status" json:"status,omitempty"`
-	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"` // Optional status message
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	TaskId  string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Status  Task_Status            `protobuf:"varint,2,opt,name=status,proto3,enum=api.v1.mesh.Task_Status" json:"status,omitempty"`
+	Message string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"` // Optional status message
+	// Optional structured details, e.g. a tool call made by the agent.
+	Data *structpb.Struct `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	// Set with a FAILED status.
+	Error         *TaskError `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *TaskStatusUpdate) Reset() {
 	*x = TaskStatusUpdate{}
-	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[10]
+	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -836,7 +1045,7 @@ func (x *TaskStatusUpdate) String() string {
 func (*TaskStatusUpdate) ProtoMessage() {}
 
 func (x *TaskStatusUpdate) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[10]
+	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -849,7 +1058,7 @@ func (x *TaskStatusUpdate) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TaskStatusUpdate.ProtoReflect.Descriptor instead.
 func (*TaskStatusUpdate) Descriptor() ([]byte, []int) {
-	return file_pkg_api_v1_mesh_mesh_proto_rawDescGZIP(), []int{10}
+	return file_pkg_api_v1_mesh_mesh_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *TaskStatusUpdate) GetTaskId() string {
@@ -873,6 +1082,20 @@ func (x *TaskStatusUpdate) GetMessage() string {
 	return ""
 }
 
+func (x *TaskStatusUpdate) GetData() *structpb.Struct {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *TaskStatusUpdate) GetError() *TaskError {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
 // TaskArtifactUpdate represents a new artifact generated by the task.
 type TaskArtifactUpdate struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -884,7 +1107,7 @@ type TaskArtifactUpdate struct {
 
 func (x *TaskArtifactUpdate) Reset() {
 	*x = TaskArtifactUpdate{}
-	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[11]
+	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -896,7 +1119,7 @@ func (x *TaskArtifactUpdate) String() string {
 func (*TaskArtifactUpdate) ProtoMessage() {}
 
 func (x *TaskArtifactUpdate) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[11]
+	mi := &file_pkg_api_v1_mesh_mesh_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -909,7 +1132,7 @@ func (x *TaskArtifactUpdate) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TaskArtifactUpdate.ProtoReflect.Descriptor instead.
 func (*TaskArtifactUpdate) Descriptor() ([]byte, []int) {
-	return file_pkg_api_v1_mesh_mesh_proto_rawDescGZIP(), []int{11}
+	return file_pkg_api_v1_mesh_mesh_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *TaskArtifactUpdate) GetTaskId() string {
@@ -930,30 +1153,47 @@ var File_pkg_api_v1_mesh_mesh_proto protoreflect.FileDescriptor
 
 const file_pkg_api_v1_mesh_mesh_proto_rawDesc = "" +
 	"\n" +
-	"\x1apkg/api/v1/mesh/mesh.proto\x12\vapi.v1.mesh\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1bgoogle/protobuf/empty.proto\"\x88\x01\n" +
+	"\x1apkg/api/v1/mesh/mesh.proto\x12\vapi.v1.mesh\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1bgoogle/protobuf/empty.proto\"\xa1\x01\n" +
 	"\x0fTaskSendRequest\x12\x1d\n" +
 	"\n" +
 	"context_id\x18\x01 \x01(\tR\tcontextId\x12&\n" +
 	"\x0ftarget_agent_id\x18\x02 \x01(\tR\rtargetAgentId\x12.\n" +
-	"\amessage\x18\x03 \x01(\v2\x14.api.v1.mesh.MessageR\amessage\" \n" +
+	"\amessage\x18\x03 \x01(\v2\x14.api.v1.mesh.MessageR\amessage\x12\x17\n" +
+	"\atask_id\x18\x04 \x01(\tR\x06taskId\" \n" +
 	"\x0eGetTaskRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\"#\n" +
 	"\x11CancelTaskRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"\xa5\x02\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"y\n" +
+	"\x10ListTasksRequest\x12\x1d\n" +
+	"\n" +
+	"context_id\x18\x01 \x01(\tR\tcontextId\x120\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x18.api.v1.mesh.Task.StatusR\x06status\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"<\n" +
+	"\x11ListTasksResponse\x12'\n" +
+	"\x05tasks\x18\x01 \x03(\v2\x11.api.v1.mesh.TaskR\x05tasks\"\x97\x03\n" +
 	"\x04Task\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x02 \x01(\tR\tsessionId\x120\n" +
 	"\x06status\x18\x03 \x01(\x0e2\x18.api.v1.mesh.Task.StatusR\x06status\x12.\n" +
 	"\ahistory\x18\x04 \x03(\v2\x14.api.v1.mesh.MessageR\ahistory\x123\n" +
-	"\tartifacts\x18\x05 \x03(\v2\x15.api.v1.mesh.ArtifactR\tartifacts\"W\n" +
+	"\tartifacts\x18\x05 \x03(\v2\x15.api.v1.mesh.ArtifactR\tartifacts\x12,\n" +
+	"\x05error\x18\x06 \x01(\v2\x16.api.v1.mesh.TaskErrorR\x05error\"\x9a\x01\n" +
 	"\x06Status\x12\x16\n" +
 	"\x12STATUS_UNSPECIFIED\x10\x00\x12\r\n" +
 	"\tSUBMITTED\x10\x01\x12\v\n" +
 	"\aWORKING\x10\x02\x12\r\n" +
 	"\tCOMPLETED\x10\x03\x12\n" +
 	"\n" +
-	"\x06FAILED\x10\x04\"\x81\x01\n" +
+	"\x06FAILED\x10\x04\x12\x12\n" +
+	"\x0eINPUT_REQUIRED\x10\x05\x12\f\n" +
+	"\bCANCELED\x10\x06\x12\f\n" +
+	"\bREJECTED\x10\a\x12\x11\n" +
+	"\rAUTH_REQUIRED\x10\b\"W\n" +
+	"\tTaskError\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1c\n" +
+	"\tretryable\x18\x03 \x01(\bR\tretryable\"\x81\x01\n" +
 	"\aMessage\x12\x12\n" +
 	"\x04role\x18\x01 \x01(\tR\x04role\x129\n" +
 	"\n" +
@@ -983,21 +1223,24 @@ const file_pkg_api_v1_mesh_mesh_proto_rawDesc = "" +
 	"\x0fartifact_update\x18\x02 \x01(\v2\x1f.api.v1.mesh.TaskArtifactUpdateH\x00R\x0eartifactUpdateB\a\n" +
 	"\x05event\"C\n" +
 	"\tTaskStart\x126\n" +
-	"\arequest\x18\x01 \x01(\v2\x1c.api.v1.mesh.TaskSendRequestR\arequest\"w\n" +
+	"\arequest\x18\x01 \x01(\v2\x1c.api.v1.mesh.TaskSendRequestR\arequest\"\xd2\x01\n" +
 	"\x10TaskStatusUpdate\x12\x17\n" +
 	"\atask_id\x18\x01 \x01(\tR\x06taskId\x120\n" +
 	"\x06status\x18\x02 \x01(\x0e2\x18.api.v1.mesh.Task.StatusR\x06status\x12\x18\n" +
-	"\amessage\x18\x03 \x01(\tR\amessage\"`\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12+\n" +
+	"\x04data\x18\x04 \x01(\v2\x17.google.protobuf.StructR\x04data\x12,\n" +
+	"\x05error\x18\x05 \x01(\v2\x16.api.v1.mesh.TaskErrorR\x05error\"`\n" +
 	"\x12TaskArtifactUpdate\x12\x17\n" +
 	"\atask_id\x18\x01 \x01(\tR\x06taskId\x121\n" +
-	"\bartifact\x18\x02 \x01(\v2\x15.api.v1.mesh.ArtifactR\bartifact2\x94\x02\n" +
+	"\bartifact\x18\x02 \x01(\v2\x15.api.v1.mesh.ArtifactR\bartifact2\xe0\x02\n" +
 	"\x0eA2AMeshService\x12;\n" +
 	"\bSendTask\x12\x1c.api.v1.mesh.TaskSendRequest\x1a\x11.api.v1.mesh.Task\x12D\n" +
 	"\n" +
 	"StreamTask\x12\x18.api.v1.mesh.StreamEvent\x1a\x18.api.v1.mesh.StreamEvent(\x010\x01\x129\n" +
 	"\aGetTask\x12\x1b.api.v1.mesh.GetTaskRequest\x1a\x11.api.v1.mesh.Task\x12D\n" +
 	"\n" +
-	"CancelTask\x12\x1e.api.v1.mesh.CancelTaskRequest\x1a\x16.google.protobuf.EmptyB8Z6github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/meshb\x06proto3"
+	"CancelTask\x12\x1e.api.v1.mesh.CancelTaskRequest\x1a\x16.google.protobuf.Empty\x12J\n" +
+	"\tListTasks\x12\x1d.api.v1.mesh.ListTasksRequest\x1a\x1e.api.v1.mesh.ListTasksResponseB8Z6github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/meshb\x06proto3"
 
 var (
 	file_pkg_api_v1_mesh_mesh_proto_rawDescOnce sync.Once
@@ -1012,53 +1255,63 @@ func file_pkg_api_v1_mesh_mesh_proto_rawDescGZIP() []byte {
 }
 
 var file_pkg_api_v1_mesh_mesh_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_pkg_api_v1_mesh_mesh_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_pkg_api_v1_mesh_mesh_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
 var file_pkg_api_v1_mesh_mesh_proto_goTypes = []any{
 	(Task_Status)(0),              // 0: api.v1.mesh.Task.Status
 	(*TaskSendRequest)(nil),       // 1: api.v1.mesh.TaskSendRequest
 	(*GetTaskRequest)(nil),        // 2: api.v1.mesh.GetTaskRequest
 	(*CancelTaskRequest)(nil),     // 3: api.v1.mesh.CancelTaskRequest
-	(*Task)(nil),                  // 4: api.v1.mesh.Task
-	(*Message)(nil),               // 5: api.v1.mesh.Message
-	(*Part)(nil),                  // 6: api.v1.mesh.Part
-	(*FilePart)(nil),              // 7: api.v1.mesh.FilePart
-	(*Artifact)(nil),              // 8: api.v1.mesh.Artifact
-	(*StreamEvent)(nil),           // 9: api.v1.mesh.StreamEvent
-	(*TaskStart)(nil),             // 10: api.v1.mesh.TaskStart
-	(*TaskStatusUpdate)(nil),      // 11: api.v1.mesh.TaskStatusUpdate
-	(*TaskArtifactUpdate)(nil),    // 12: api.v1.mesh.TaskArtifactUpdate
-	(*timestamppb.Timestamp)(nil), // 13: google.protobuf.Timestamp
-	(*structpb.Struct)(nil),       // 14: google.protobuf.Struct
-	(*emptypb.Empty)(nil),         // 15: google.protobuf.Empty
+	(*ListTasksRequest)(nil),      // 4: api.v1.mesh.ListTasksRequest
+	(*ListTasksResponse)(nil),     // 5: api.v1.mesh.ListTasksResponse
+	(*Task)(nil),                  // 6: api.v1.mesh.Task
+	(*TaskError)(nil),             // 7: api.v1.mesh.TaskError
+	(*Message)(nil),               // 8: api.v1.mesh.Message
+	(*Part)(nil),                  // 9: api.v1.mesh.Part
+	(*FilePart)(nil),              // 10: api.v1.mesh.FilePart
+	(*Artifact)(nil),              // 11: api.v1.mesh.Artifact
+	(*StreamEvent)(nil),           // 12: api.v1.mesh.StreamEvent
+	(*TaskStart)(nil),             // 13: api.v1.mesh.TaskStart
+	(*TaskStatusUpdate)(nil),      // 14: api.v1.mesh.TaskStatusUpdate
+	(*TaskArtifactUpdate)(nil),    // 15: api.v1.mesh.TaskArtifactUpdate
+	(*timestamppb.Timestamp)(nil), // 16: google.protobuf.Timestamp
+	(*structpb.Struct)(nil),       // 17: google.protobuf.Struct
+	(*emptypb.Empty)(nil),         // 18: google.protobuf.Empty
 }
 var file_pkg_api_v1_mesh_mesh_proto_depIdxs = []int32{
-	5,  // 0: api.v1.mesh.TaskSendRequest.message:type_name -> api.v1.mesh.Message
-	0,  // 1: api.v1.mesh.Task.status:type_name -> api.v1.mesh.Task.Status
-	5,  // 2: api.v1.mesh.Task.history:type_name -> api.v1.mesh.Message
-	8,  // 3: api.v1.mesh.Task.artifacts:type_name -> api.v1.mesh.Artifact
-	13, // 4: api.v1.mesh.Message.created_at:type_name -> google.protobuf.Timestamp
-	6,  // 5: api.v1.mesh.Message.parts:type_name -> api.v1.mesh.Part
-	7,  // 6: api.v1.mesh.Part.file_part:type_name -> api.v1.mesh.FilePart
-	14, // 7: api.v1.mesh.Part.data_part:type_name -> google.protobuf.Struct
-	10, // 8: api.v1.mesh.StreamEvent.task_start:type_name -> api.v1.mesh.TaskStart
-	11, // 9: api.v1.mesh.StreamEvent.status_update:type_name -> api.v1.mesh.TaskStatusUpdate
-	12, // 10: api.v1.mesh.StreamEvent.artifact_update:type_name -> api.v1.mesh.TaskArtifactUpdate
-	1,  // 11: api.v1.mesh.TaskStart.request:type_name -> api.v1.mesh.TaskSendRequest
-	0,  // 12: api.v1.mesh.TaskStatusUpdate.status:type_name -> api.v1.mesh.Task.Status
-	8,  // 13: api.v1.mesh.TaskArtifactUpdate.artifact:type_name -> api.v1.mesh.Artifact
-	1,  // 14: api.v1.mesh.A2AMeshService.SendTask:input_type -> api.v1.mesh.TaskSendRequest
-	9,  // 15: api.v1.mesh.A2AMeshService.StreamTask:input_type -> api.v1.mesh.StreamEvent
-	2,  // 16: api.v1.mesh.A2AMeshService.GetTask:input_type -> api.v1.mesh.GetTaskRequest
-	3,  // 17: api.v1.mesh.A2AMeshService.CancelTask:input_type -> api.v1.mesh.CancelTaskRequest
-	4,  // 18: api.v1.mesh.A2AMeshService.SendTask:output_type -> api.v1.mesh.Task
-	9,  // 19: api.v1.mesh.A2AMeshService.StreamTask:output_type -> api.v1.mesh.StreamEvent
-	4,  // 20: api.v1.mesh.A2AMeshService.GetTask:output_type -> api.v1.mesh.Task
-	15, // 21: api.v1.mesh.A2AMeshService.CancelTask:output_type -> google.protobuf.Empty
-	18, // [18:22] is the sub-list for method output_type
-	14, // [14:18] is the sub-list for method input_type
-	14, // [14:14] is the sub-list for extension type_name
-	14, // [14:14] is the sub-list for extension extendee
-	0,  // [0:14] is the sub-list for field type_name
+	8,  // 0: api.v1.mesh.TaskSendRequest.message:type_name -> api.v1.mesh.Message
+	0,  // 1: api.v1.mesh.ListTasksRequest.status:type_name -> api.v1.mesh.Task.Status
+	6,  // 2: api.v1.mesh.ListTasksResponse.tasks:type_name -> api.v1.mesh.Task
+	0,  // 3: api.v1.mesh.Task.status:type_name -> api.v1.mesh.Task.Status
+	8,  // 4: api.v1.mesh.Task.history:type_name -> api.v1.mesh.Message
+	11, // 5: api.v1.mesh.Task.artifacts:type_name -> api.v1.mesh.Artifact
+	7,  // 6: api.v1.mesh.Task.error:type_name -> api.v1.mesh.TaskError
+	16, // 7: api.v1.mesh.Message.created_at:type_name -> google.protobuf.Timestamp
+	9,  // 8: api.v1.mesh.Message.parts:type_name -> api.v1.mesh.Part
+	10, // 9: api.v1.mesh.Part.file_part:type_name -> api.v1.mesh.FilePart
+	17, // 10: api.v1.mesh.Part.data_part:type_name -> google.protobuf.Struct
+	13, // 11: api.v1.mesh.StreamEvent.task_start:type_name -> api.v1.mesh.TaskStart
+	14, // 12: api.v1.mesh.StreamEvent.status_update:type_name -> api.v1.mesh.TaskStatusUpdate
+	15, // 13: api.v1.mesh.StreamEvent.artifact_update:type_name -> api.v1.mesh.TaskArtifactUpdate
+	1,  // 14: api.v1.mesh.TaskStart.request:type_name -> api.v1.mesh.TaskSendRequest
+	0,  // 15: api.v1.mesh.TaskStatusUpdate.status:type_name -> api.v1.mesh.Task.Status
+	17, // 16: api.v1.mesh.TaskStatusUpdate.data:type_name -> google.protobuf.Struct
+	7,  // 17: api.v1.mesh.TaskStatusUpdate.error:type_name -> api.v1.mesh.TaskError
+	11, // 18: api.v1.mesh.TaskArtifactUpdate.artifact:type_name -> api.v1.mesh.Artifact
+	1,  // 19: api.v1.mesh.A2AMeshService.SendTask:input_type -> api.v1.mesh.TaskSendRequest
+	12, // 20: api.v1.mesh.A2AMeshService.StreamTask:input_type -> api.v1.mesh.StreamEvent
+	2,  // 21: api.v1.mesh.A2AMeshService.GetTask:input_type -> api.v1.mesh.GetTaskRequest
+	3,  // 22: api.v1.mesh.A2AMeshService.CancelTask:input_type -> api.v1.mesh.CancelTaskRequest
+	4,  // 23: api.v1.mesh.A2AMeshService.ListTasks:input_type -> api.v1.mesh.ListTasksRequest
+	6,  // 24: api.v1.mesh.A2AMeshService.SendTask:output_type -> api.v1.mesh.Task
+	12, // 25: api.v1.mesh.A2AMeshService.StreamTask:output_type -> api.v1.mesh.StreamEvent
+	6,  // 26: api.v1.mesh.A2AMeshService.GetTask:output_type -> api.v1.mesh.Task
+	18, // 27: api.v1.mesh.A2AMeshService.CancelTask:output_type -> google.protobuf.Empty
+	5,  // 28: api.v1.mesh.A2AMeshService.ListTasks:output_type -> api.v1.mesh.ListTasksResponse
+	24, // [24:29] is the sub-list for method output_type
+	19, // [19:24] is the sub-list for method input_type
+	19, // [19:19] is the sub-list for extension type_name
+	19, // [19:19] is the sub-list for extension extendee
+	0,  // [0:19] is the sub-list for field type_name
 }
 
 func init() { file_pkg_api_v1_mesh_mesh_proto_init() }
@@ -1066,20 +1319,20 @@ func file_pkg_api_v1_mesh_mesh_proto_init() {
 	if File_pkg_api_v1_mesh_mesh_proto != nil {
 		return
 	}
-	file_pkg_api_v1_mesh_mesh_proto_msgTypes[5].OneofWrappers = []any{
+	file_pkg_api_v1_mesh_mesh_proto_msgTypes[8].OneofWrappers = []any{
 		(*Part_TextPart)(nil),
 		(*Part_FilePart)(nil),
 		(*Part_DataPart)(nil),
 	}
-	file_pkg_api_v1_mesh_mesh_proto_msgTypes[6].OneofWrappers = []any{
+	file_pkg_api_v1_mesh_mesh_proto_msgTypes[9].OneofWrappers = []any{
 		(*FilePart_InlineBytes)(nil),
 		(*FilePart_Uri)(nil),
 	}
-	file_pkg_api_v1_mesh_mesh_proto_msgTypes[7].OneofWrappers = []any{
+	file_pkg_api_v1_mesh_mesh_proto_msgTypes[10].OneofWrappers = []any{
 		(*Artifact_Bytes)(nil),
 		(*Artifact_Uri)(nil),
 	}
-	file_pkg_api_v1_mesh_mesh_proto_msgTypes[8].OneofWrappers = []any{
+	file_pkg_api_v1_mesh_mesh_proto_msgTypes[11].OneofWrappers = []any{
 		(*StreamEvent_TaskStart)(nil),
 		(*StreamEvent_StatusUpdate)(nil),
 		(*StreamEvent_ArtifactUpdate)(nil),
@@ -1090,7 +1343,7 @@ func file_pkg_api_v1_mesh_mesh_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pkg_api_v1_mesh_mesh_proto_rawDesc), len(file_pkg_api_v1_mesh_mesh_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   12,
+			NumMessages:   15,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -24,6 +24,7 @@ const (
 	A2AMeshService_StreamTask_FullMethodName = "/api.v1.mesh.A2AMeshService/StreamTask"
 	A2AMeshService_GetTask_FullMethodName    = "/api.v1.mesh.A2AMeshService/GetTask"
 	A2AMeshService_CancelTask_FullMethodName = "/api.v1.mesh.A2AMeshService/CancelTask"
+	A2AMeshService_ListTasks_FullMethodName  = "/api.v1.mesh.A2AMeshService/ListTasks"
 )
 
 // A2AMeshServiceClient is the client API for A2AMeshService service.
@@ -40,6 +41,8 @@ type A2AMeshServiceClient interface {
 	GetTask(ctx context.Context, in *GetTaskRequest, opts ...grpc.CallOption) (*Task, error)
 	// Cancel an ongoing task.
 	CancelTask(ctx context.Context, in *CancelTaskRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// List the tasks the sidecar has seen, most recently updated first.
+	ListTasks(ctx context.Context, in *ListTasksRequest, opts ...grpc.CallOption) (*ListTasksResponse, error)
 }
 
 type a2AMeshServiceClient struct {
@@ -93,6 +96,16 @@ func (c *a2AMeshServiceClient) CancelTask(ctx context.Context, in *CancelTaskReq
 	return out, nil
 }
 
+func (c *a2AMeshServiceClient) ListTasks(ctx context.Context, in *ListTasksRequest, opts ...grpc.CallOption) (*ListTasksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTasksResponse)
+	err := c.cc.Invoke(ctx, A2AMeshService_ListTasks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // A2AMeshServiceServer is the server API for A2AMeshService service.
 // All implementations must embed UnimplementedA2AMeshServiceServer
 // for forward compatibility.
@@ -107,6 +120,8 @@ type A2AMeshServiceServer interface {
 	GetTask(context.Context, *GetTaskRequest) (*Task, error)
 	// Cancel an ongoing task.
 	CancelTask(context.Context, *CancelTaskRequest) (*emptypb.Empty, error)
+	// List the tasks the sidecar has seen, most recently updated first.
+	ListTasks(context.Context, *ListTasksRequest) (*ListTasksResponse, error)
 	mustEmbedUnimplementedA2AMeshServiceServer()
 }
 
@@ -129,6 +144,9 @@ func (UnimplementedA2AMeshServiceServer) GetTask(context.Context, *GetTaskReques
 func (UnimplementedA2AMeshServiceServer) CancelTask(context.Context, *CancelTaskRequest) (*emptypb.Empty, error) {
 	return nil, status.Error(codes.Unimplemented, "method CancelTask not implemented")
 }
+func (UnimplementedA2AMeshServiceServer) ListTasks(context.Context, *ListTasksRequest) (*ListTasksResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListTasks not implemented")
+}
 func (UnimplementedA2AMeshServiceServer) mustEmbedUnimplementedA2AMeshServiceServer() {}
 func (UnimplementedA2AMeshServiceServer) testEmbeddedByValue()                        {}
 
@@ -211,6 +229,24 @@ func _A2AMeshService_CancelTask_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _A2AMeshService_ListTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTasksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(A2AMeshServiceServer).ListTasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: A2AMeshService_ListTasks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(A2AMeshServiceServer).ListTasks(ctx, req.(*ListTasksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // A2AMeshService_ServiceDesc is the grpc.ServiceDesc for A2AMeshService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -230,6 +266,10 @@ var A2AMeshService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CancelTask",
 			Handler:    _A2AMeshService_CancelTask_Handler,
 		},
+		{
+			MethodName: "ListTasks",
+			Handler:    _A2AMeshService_ListTasks_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -0,0 +1,51 @@
+// Package lifecycle defines the statuses a mesh task moves through and the
+// transitions allowed between them.
+package lifecycle
+
+import (
+	"fmt"
+
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+)
+
+// transitions lists the statuses a task may move to from each non-terminal status.
+// Repeating a non-terminal status (e.g. WORKING progress updates) is always allowed.
+var transitions = map[mesh.Task_Status][]mesh.Task_Status{
+	mesh.Task_SUBMITTED:      {mesh.Task_WORKING, mesh.Task_INPUT_REQUIRED, mesh.Task_AUTH_REQUIRED, mesh.Task_COMPLETED, mesh.Task_FAILED, mesh.Task_CANCELED, mesh.Task_REJECTED},
+	mesh.Task_WORKING:        {mesh.Task_INPUT_REQUIRED, mesh.Task_AUTH_REQUIRED, mesh.Task_COMPLETED, mesh.Task_FAILED, mesh.Task_CANCELED},
+	mesh.Task_INPUT_REQUIRED: {mesh.Task_WORKING, mesh.Task_COMPLETED, mesh.Task_FAILED, mesh.Task_CANCELED},
+	mesh.Task_AUTH_REQUIRED:  {mesh.Task_WORKING, mesh.Task_FAILED, mesh.Task_CANCELED, mesh.Task_REJECTED},
+}
+
+// IsTerminal reports whether a task in status s can no longer change.
+func IsTerminal(s mesh.Task_Status) bool {
+	switch s {
+	case mesh.Task_COMPLETED, mesh.Task_FAILED, mesh.Task_CANCELED, mesh.Task_REJECTED:
+		return true
+	}
+	return false
+}
+
+// IsInterrupted reports whether a task in status s is waiting for the client.
+func IsInterrupted(s mesh.Task_Status) bool {
+	return s == mesh.Task_INPUT_REQUIRED || s == mesh.Task_AUTH_REQUIRED
+}
+
+// ValidateTransition returns an error if a task may not move from one status to the other.
+func ValidateTransition(from, to mesh.Task_Status) error {
+	if to == mesh.Task_STATUS_UNSPECIFIED {
+		return fmt.Errorf("invalid transition from %s to %s: status is required", from, to)
+	}
+	if IsTerminal(from) {
+		return fmt.Errorf("invalid transition from %s to %s: task is already %s", from, to, from)
+	}
+	if from == to {
+		return nil
+	}
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid transition from %s to %s", from, to)
+}
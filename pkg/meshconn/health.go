@@ -0,0 +1,40 @@
+package meshconn
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// WatchHealth runs check every interval and mirrors its result into hs, both for the
+// whole server ("") and for service. A failing check is logged under name. It blocks
+// until ctx is cancelled, then marks the server NOT_SERVING.
+func WatchHealth(ctx context.Context, hs *health.Server, service, name string, interval time.Duration, check func(context.Context) error) {
+	update := func() {
+		status := healthpb.HealthCheckResponse_SERVING
+		if err := check(ctx); err != nil {
+			log.Printf("%s not serving: %v", name, err)
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		hs.SetServingStatus("", status)
+		hs.SetServingStatus(service, status)
+	}
+
+	update()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			hs.Shutdown()
+			return
+		case <-ticker.C:
+			update()
+		}
+	}
+}
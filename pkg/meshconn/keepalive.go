@@ -0,0 +1,36 @@
+// Package meshconn holds the gRPC plumbing shared by the Registry, the Sidecar and the
+// ADK adapter: keepalive settings both ends of a connection agree on, and health reporting.
+package meshconn
+
+import (
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// Clients ping an idle connection every 30s, so that a connection the server dropped
+// is noticed and redialed before the next call rather than by it. Servers permit pings
+// twice as often, so that they never close a connection for pinging too much.
+var (
+	clientParameters = keepalive.ClientParameters{
+		Time:                30 * time.Second,
+		Timeout:             10 * time.Second,
+		PermitWithoutStream: true,
+	}
+	enforcementPolicy = keepalive.EnforcementPolicy{
+		MinTime:             15 * time.Second,
+		PermitWithoutStream: true,
+	}
+)
+
+// KeepaliveDialOption sets the keepalive pings of a client connection to a Sidecar.
+func KeepaliveDialOption() grpc.DialOption {
+	return grpc.WithKeepaliveParams(clientParameters)
+}
+
+// KeepaliveServerOption lets clients ping a Sidecar's listener as often as
+// KeepaliveDialOption does.
+func KeepaliveServerOption() grpc.ServerOption {
+	return grpc.KeepaliveEnforcementPolicy(enforcementPolicy)
+}
@@ -0,0 +1,118 @@
+package meshtest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Certs holds PEM files for an ephemeral CA and a leaf usable as both
+// server and client certificate for localhost.
+type Certs struct {
+	CAFile, CertFile, KeyFile string
+}
+
+// CA is an ephemeral certificate authority, valid for an hour.
+type CA struct {
+	t    testing.TB
+	dir  string
+	file string
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+
+	mu     sync.Mutex
+	serial int64
+}
+
+// NewCA creates a CA whose files are written to a temporary directory removed when
+// the test ends.
+func NewCA(t testing.TB) *CA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	check(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "agentmesh-test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	check(t, err)
+	cert, err := x509.ParseCertificate(der)
+	check(t, err)
+
+	ca := &CA{t: t, dir: t.TempDir(), cert: cert, key: key, serial: 1}
+	ca.file = filepath.Join(ca.dir, "ca-cert.pem")
+	writePEM(t, ca.file, "CERTIFICATE", der)
+	return ca
+}
+
+// Issue writes a certificate for commonName, signed by the CA, and returns its files.
+func (ca *CA) Issue(commonName string) Certs {
+	ca.t.Helper()
+	ca.mu.Lock()
+	ca.serial++
+	serial := ca.serial
+	ca.mu.Unlock()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	check(ca.t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	check(ca.t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	check(ca.t, err)
+
+	name := strconv.FormatInt(serial, 10)
+	certs := Certs{
+		CAFile:   ca.file,
+		CertFile: filepath.Join(ca.dir, name+"-cert.pem"),
+		KeyFile:  filepath.Join(ca.dir, name+"-key.pem"),
+	}
+	writePEM(ca.t, certs.CertFile, "CERTIFICATE", der)
+	writePEM(ca.t, certs.KeyFile, "EC PRIVATE KEY", keyDER)
+	return certs
+}
+
+// GenerateCerts writes a new CA and a certificate for commonName, valid for an hour,
+// to a temporary directory removed when the test ends.
+func GenerateCerts(t testing.TB, commonName string) Certs {
+	t.Helper()
+	return NewCA(t).Issue(commonName)
+}
+
+func writePEM(t testing.TB, path, blockType string, der []byte) {
+	t.Helper()
+	check(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+}
+
+// check ends the test on err.
+func check(t testing.TB, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,318 @@
+// Package meshtest runs an AgentMesh in one process for tests: a registry, sidecars
+// with ephemeral certificates and listeners, and the agents behind them.
+package meshtest
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ThisaraWeerakoon/Agent-Mesh/internal/adapters/auth"
+	grpcHandler "github.com/ThisaraWeerakoon/Agent-Mesh/internal/adapters/handler/grpc"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/internal/adapters/repository/memory"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/internal/config"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/internal/core/services"
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+	registry "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/registry"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/sidecar"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/telemetry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// waitTimeout bounds how long the harness waits for a sidecar to listen or an agent to register.
+const waitTimeout = 2 * time.Second
+
+// defaultCommonName names the certificates of the harness itself and of sidecars without an AgentID.
+const defaultCommonName = "meshtest"
+
+// Config configures a Mesh.
+type Config struct {
+	// RegistryAddr is the registry the sidecars use, in plaintext. Without it, a
+	// registry with in-memory storage is started.
+	RegistryAddr string
+	// Auth serves the started registry over mTLS with caller authentication, so each
+	// sidecar is the registry principal named by its certificate. Ignored with a RegistryAddr.
+	Auth bool
+}
+
+// Mesh is an AgentMesh running until the test ends. Each sidecar gets a certificate
+// of its own, issued by an ephemeral CA for its agent ID.
+type Mesh struct {
+	t            testing.TB
+	registryAddr string
+	// registryCA is the CA file verifying the registry, or "" when it serves plaintext.
+	registryCA string
+	registry   registry.RegistryServiceClient
+	ca         *CA
+}
+
+// New starts a mesh as configured by cfg. It is stopped when the test ends.
+func New(t testing.TB, cfg Config) *Mesh {
+	t.Helper()
+	m := &Mesh{t: t, registryAddr: cfg.RegistryAddr, ca: NewCA(t)}
+	if m.registryAddr == "" {
+		if cfg.Auth {
+			m.registryAddr = StartAuthRegistry(t, m.ca.Issue("registry"))
+			m.registryCA = m.ca.file
+		} else {
+			m.registryAddr = StartRegistry(t)
+		}
+	}
+	m.registry = m.RegistryAs(defaultCommonName)
+	return m
+}
+
+// RegistryAddr returns the address of the mesh's registry.
+func (m *Mesh) RegistryAddr() string {
+	return m.registryAddr
+}
+
+// Registry returns a client for the mesh's registry.
+func (m *Mesh) Registry() registry.RegistryServiceClient {
+	return m.registry
+}
+
+// RegistryAs returns a client for the mesh's registry presenting a certificate for
+// commonName, which a registry started with Auth takes as the caller's principal.
+func (m *Mesh) RegistryAs(commonName string) registry.RegistryServiceClient {
+	m.t.Helper()
+	creds := insecure.NewCredentials()
+	if m.registryCA != "" {
+		certs := m.Issue(commonName)
+		cert, err := tls.LoadX509KeyPair(certs.CertFile, certs.KeyFile)
+		check(m.t, err)
+		pool := x509.NewCertPool()
+		pool.AddCert(m.ca.cert)
+		creds = credentials.NewTLS(&tls.Config{Certificates: []tls.Certificate{cert}, RootCAs: pool})
+	}
+	conn, err := grpc.NewClient(m.registryAddr, grpc.WithTransportCredentials(creds))
+	check(m.t, err)
+	m.t.Cleanup(func() { conn.Close() })
+	return registry.NewRegistryServiceClient(conn)
+}
+
+// Issue issues a certificate for commonName from the mesh's CA.
+func (m *Mesh) Issue(commonName string) Certs {
+	m.t.Helper()
+	return m.ca.Issue(commonName)
+}
+
+// AddAgent serves agent behind a new sidecar, which registers it under agentID with
+// card, an AgentCard in its A2A JSON form. It returns once the agent is registered.
+func (m *Mesh) AddAgent(agentID, card string, agent mesh.A2AMeshServiceServer) *Sidecar {
+	m.t.Helper()
+	cardFile := filepath.Join(m.t.TempDir(), "card.json")
+	check(m.t, os.WriteFile(cardFile, []byte(card), 0o600))
+
+	s := m.StartSidecar(sidecar.Config{
+		AgentID:       agentID,
+		AppPort:       ServeAgent(m.t, agent),
+		AgentCardFile: cardFile,
+	})
+	m.WaitRegistered(agentID)
+	return s
+}
+
+// AddCaller starts a sidecar for an agent that only calls others, e.g. an ADK
+// application using RemoteTools.
+func (m *Mesh) AddCaller(agentID string) *Sidecar {
+	m.t.Helper()
+	return m.StartSidecar(sidecar.Config{AgentID: agentID})
+}
+
+// StartSidecar starts a sidecar with cfg. The registry, certificates and listeners
+// that cfg leaves empty are filled in by the mesh: the certificate is issued for the
+// AgentID, and listeners are opened on ephemeral ports, whose numbers are set in the
+// returned Config. A sidecar without an AppPort has no local agent.
+func (m *Mesh) StartSidecar(cfg sidecar.Config) *Sidecar {
+	m.t.Helper()
+	if cfg.RegistryURL == "" {
+		cfg.RegistryURL, cfg.RegistryCAFile = m.registryAddr, m.registryCA
+	}
+	if cfg.CertFile == "" {
+		commonName := cfg.AgentID
+		if commonName == "" {
+			commonName = defaultCommonName
+		}
+		certs := m.Issue(commonName)
+		cfg.CertFile, cfg.KeyFile, cfg.CAFile = certs.CertFile, certs.KeyFile, certs.CAFile
+	}
+	if cfg.LocalPort == 0 && cfg.LocalListener == nil {
+		cfg.LocalListener = Listen(m.t)
+	}
+	if cfg.ExternalPort == 0 && cfg.ExternalListener == nil {
+		cfg.ExternalListener = Listen(m.t)
+	}
+	if cfg.LocalListener != nil {
+		cfg.LocalPort = cfg.LocalListener.Addr().(*net.TCPAddr).Port
+	}
+	if cfg.ExternalListener != nil {
+		cfg.ExternalPort = cfg.ExternalListener.Addr().(*net.TCPAddr).Port
+	}
+	client, stop := startSidecar(m.t, cfg)
+	return &Sidecar{t: m.t, Config: cfg, Client: client, stop: stop}
+}
+
+// WaitRegistered waits until the registry knows agentID and fails the test otherwise.
+func (m *Mesh) WaitRegistered(agentID string) {
+	m.t.Helper()
+	deadline := time.Now().Add(waitTimeout)
+	for {
+		_, err := m.registry.GetAgent(context.Background(), &registry.GetAgentRequest{AgentId: agentID})
+		if err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			m.t.Fatalf("agent %s was not registered: %v", agentID, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Sidecar is a sidecar of a Mesh.
+type Sidecar struct {
+	t testing.TB
+	// Config is the configuration the sidecar runs with.
+	Config sidecar.Config
+	// Client is a client for the sidecar's local listener.
+	Client mesh.A2AMeshServiceClient
+
+	stop func() error
+}
+
+// Stop stops the sidecar before the test ends and returns the error its Run returned.
+func (s *Sidecar) Stop() error {
+	return s.stop()
+}
+
+// LocalAddr returns the address of the sidecar's local listener.
+func (s *Sidecar) LocalAddr() string {
+	return fmt.Sprintf("127.0.0.1:%d", s.Config.LocalPort)
+}
+
+// UseForRemoteTools points the RemoteTools and RemoteAgents of the test at this
+// sidecar by setting AGENTMESH_SIDECAR_PORT, which makes the test non-parallel.
+func (s *Sidecar) UseForRemoteTools() {
+	s.t.Setenv("AGENTMESH_SIDECAR_PORT", strconv.Itoa(s.Config.LocalPort))
+}
+
+// StartRegistry serves the registry gRPC API with in-memory storage on an ephemeral
+// port until the test ends, and returns its address.
+func StartRegistry(t testing.TB) string {
+	t.Helper()
+	service := services.NewRegistryService(memory.NewRegistryRepository())
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	check(t, err)
+	s := grpc.NewServer()
+	registry.RegisterRegistryServiceServer(s, grpcHandler.NewRegistryServer(service))
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	return lis.Addr().String()
+}
+
+// StartAuthRegistry serves the registry gRPC API with in-memory storage on an
+// ephemeral port until the test ends, and returns its address. It serves over TLS
+// with certs, and authenticates callers by client certificates issued by the CA of
+// certs: each is the principal named by its common name.
+func StartAuthRegistry(t testing.TB, certs Certs) string {
+	t.Helper()
+	service := services.NewRegistryService(memory.NewRegistryRepository())
+	tlsConfig, err := config.TLSConfig{CertFile: certs.CertFile, KeyFile: certs.KeyFile, ClientCAFile: certs.CAFile}.ServerConfig()
+	check(t, err)
+	authenticator := auth.NewAuthenticator(config.AuthConfig{Mode: "mtls"})
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	check(t, err)
+	s := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(tlsConfig)),
+		grpc.UnaryInterceptor(grpcHandler.UnaryAuthInterceptor(authenticator)),
+		grpc.StreamInterceptor(grpcHandler.StreamAuthInterceptor(authenticator)),
+	)
+	registry.RegisterRegistryServiceServer(s, grpcHandler.NewRegistryServer(service))
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	return lis.Addr().String()
+}
+
+// StartSidecar runs a sidecar with cfg until the test ends and returns a client for
+// its local listener.
+func StartSidecar(t testing.TB, cfg sidecar.Config) mesh.A2AMeshServiceClient {
+	t.Helper()
+	client, _ := startSidecar(t, cfg)
+	return client
+}
+
+// startSidecar runs a sidecar with cfg until the test ends or stop is called, and
+// waits for its local listener to answer.
+func startSidecar(t testing.TB, cfg sidecar.Config) (client mesh.A2AMeshServiceClient, stop func() error) {
+	t.Helper()
+	srv, err := sidecar.NewServer(cfg)
+	check(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Run(ctx) }()
+	stop = sync.OnceValue(func() error {
+		cancel()
+		return <-done
+	})
+	t.Cleanup(func() { stop() })
+
+	addr := fmt.Sprintf("127.0.0.1:%d", cfg.LocalPort)
+	if cfg.LocalListener != nil {
+		addr = cfg.LocalListener.Addr().String()
+	}
+	deadline := time.Now().Add(waitTimeout)
+	for {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("sidecar did not listen on %s: %v", addr, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	check(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return mesh.NewA2AMeshServiceClient(conn), stop
+}
+
+// ServeAgent serves agent on an ephemeral port until the test ends and returns the port.
+// Like adk.NewAgentServer, the server continues the trace of the incoming task.
+func ServeAgent(t testing.TB, agent mesh.A2AMeshServiceServer) int {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	check(t, err)
+	s := grpc.NewServer(telemetry.ServerOption())
+	mesh.RegisterA2AMeshServiceServer(s, agent)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	return lis.Addr().(*net.TCPAddr).Port
+}
+
+// Listen opens a TCP listener on an ephemeral localhost port, closed when the test
+// ends unless a server has closed it before.
+func Listen(t testing.TB) net.Listener {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	check(t, err)
+	t.Cleanup(func() { lis.Close() })
+	return lis
+}
@@ -0,0 +1,195 @@
+package meshtest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/metadata"
+)
+
+// Text is a user message holding text.
+func Text(text string) *mesh.Message {
+	return &mesh.Message{Role: "user", Parts: []*mesh.Part{{Content: &mesh.Part_TextPart{TextPart: text}}}}
+}
+
+// SendTask sends req through the sidecar to the agent registered as agentID and
+// returns the events of the task once its stream ends.
+func (s *Sidecar) SendTask(ctx context.Context, agentID string, req *mesh.TaskSendRequest) (Events, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-target-agent", agentID)
+	stream, err := s.Client.StreamTask(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = stream.Send(&mesh.StreamEvent{Event: &mesh.StreamEvent_TaskStart{TaskStart: &mesh.TaskStart{Request: req}}})
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	var events Events
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return events, err
+		}
+		events = append(events, event)
+	}
+}
+
+// Ask sends text to the agent registered as agentID and returns the task's events.
+// It fails the test if the task cannot be sent.
+func (s *Sidecar) Ask(agentID, text string) Events {
+	s.t.Helper()
+	events, err := s.SendTask(context.Background(), agentID, &mesh.TaskSendRequest{Message: Text(text)})
+	check(s.t, err)
+	return events
+}
+
+// Events are the events received for a task.
+type Events []*mesh.StreamEvent
+
+// Statuses returns the statuses the task went through.
+func (e Events) Statuses() []mesh.Task_Status {
+	var statuses []mesh.Task_Status
+	for _, event := range e {
+		if update := event.GetStatusUpdate(); update != nil {
+			statuses = append(statuses, update.Status)
+		}
+	}
+	return statuses
+}
+
+// Messages returns the messages of the task's status updates.
+func (e Events) Messages() []string {
+	var messages []string
+	for _, event := range e {
+		if msg := event.GetStatusUpdate().GetMessage(); msg != "" {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+// Artifacts returns the task's artifacts.
+func (e Events) Artifacts() []*mesh.Artifact {
+	var artifacts []*mesh.Artifact
+	for _, event := range e {
+		if artifact := event.GetArtifactUpdate().GetArtifact(); artifact != nil {
+			artifacts = append(artifacts, artifact)
+		}
+	}
+	return artifacts
+}
+
+// Response returns the content of the "response" artifacts, as sent by ServerWrapper.
+func (e Events) Response() []string {
+	var texts []string
+	for _, artifact := range e.Artifacts() {
+		if artifact.Name == "response" {
+			texts = append(texts, string(artifact.GetBytes()))
+		}
+	}
+	return texts
+}
+
+// Final returns the last status update, or nil.
+func (e Events) Final() *mesh.TaskStatusUpdate {
+	for _, event := range slices.Backward(e) {
+		if update := event.GetStatusUpdate(); update != nil {
+			return update
+		}
+	}
+	return nil
+}
+
+// AssertStatuses reports an error unless the task went through want, in order.
+func (e Events) AssertStatuses(t testing.TB, want ...mesh.Task_Status) {
+	t.Helper()
+	if got := e.Statuses(); !slices.Equal(got, want) {
+		t.Errorf("task statuses = %v, want %v", got, want)
+	}
+}
+
+// AssertResponse reports an error unless the task completed with want as its response.
+func (e Events) AssertResponse(t testing.TB, want ...string) {
+	t.Helper()
+	if final := e.Final(); final.GetStatus() != mesh.Task_COMPLETED {
+		t.Errorf("task ended with %v (%q), want COMPLETED", final.GetStatus(), final.GetMessage())
+	}
+	if got := e.Response(); !slices.Equal(got, want) {
+		t.Errorf("task response = %q, want %q", got, want)
+	}
+}
+
+// ReplyFunc answers a task with text. An error fails the task.
+type ReplyFunc func(ctx context.Context, req *mesh.TaskSendRequest) (string, error)
+
+// replyAgent is a mock agent answering each task with its ReplyFunc.
+type replyAgent struct {
+	mesh.UnimplementedA2AMeshServiceServer
+	reply ReplyFunc
+}
+
+// NewAgent returns a mock agent answering each task with the text reply returns,
+// sent as a "response" artifact like ServerWrapper does.
+func NewAgent(reply ReplyFunc) mesh.A2AMeshServiceServer {
+	return &replyAgent{reply: reply}
+}
+
+// StreamTask implements mesh.A2AMeshServiceServer.
+func (a *replyAgent) StreamTask(stream mesh.A2AMeshService_StreamTaskServer) error {
+	event, err := stream.Recv()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	req := event.GetTaskStart().GetRequest()
+	if req == nil {
+		return errors.New("first event is not a TaskStart")
+	}
+
+	taskID := req.TaskId
+	if taskID == "" {
+		taskID = uuid.NewString()
+	}
+	if err := sendStatus(stream, taskID, mesh.Task_WORKING, ""); err != nil {
+		return err
+	}
+	text, err := a.reply(stream.Context(), req)
+	if err != nil {
+		return sendStatus(stream, taskID, mesh.Task_FAILED, err.Error())
+	}
+	err = stream.Send(&mesh.StreamEvent{Event: &mesh.StreamEvent_ArtifactUpdate{ArtifactUpdate: &mesh.TaskArtifactUpdate{
+		TaskId: taskID,
+		Artifact: &mesh.Artifact{
+			Id:       uuid.NewString(),
+			Name:     "response",
+			MimeType: "text/plain",
+			Content:  &mesh.Artifact_Bytes{Bytes: []byte(text)},
+		},
+	}}})
+	if err != nil {
+		return err
+	}
+	return sendStatus(stream, taskID, mesh.Task_COMPLETED, "")
+}
+
+func sendStatus(stream mesh.A2AMeshService_StreamTaskServer, taskID string, status mesh.Task_Status, message string) error {
+	return stream.Send(&mesh.StreamEvent{Event: &mesh.StreamEvent_StatusUpdate{StatusUpdate: &mesh.TaskStatusUpdate{
+		TaskId:  taskID,
+		Status:  status,
+		Message: message,
+	}}})
+}
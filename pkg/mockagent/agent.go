@@ -0,0 +1,243 @@
+package mockagent
+
+import (
+	"context"
+	"io"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+	registry "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/registry"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/lifecycle"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Agent is a mesh agent playing a Scenario. It implements the A2AMeshService interface.
+type Agent struct {
+	mesh.UnimplementedA2AMeshServiceServer
+	scenario *Scenario
+
+	// mu guards paused, which maps the IDs of tasks waiting for input to where they stopped.
+	mu     sync.Mutex
+	paused map[string]*run
+}
+
+// run is a task playing a script.
+type run struct {
+	taskID string
+	skill  string
+	steps  []Step
+	// next is the index of the next step.
+	next int
+	// input is the text of the last message received for the task.
+	input string
+}
+
+// New creates an agent playing sc.
+func New(sc *Scenario) *Agent {
+	return &Agent{scenario: sc, paused: make(map[string]*run)}
+}
+
+// Card describes the agent, with a skill for each scripted skill but DefaultSkill.
+func (a *Agent) Card() *registry.AgentCard {
+	name := a.scenario.Name
+	if name == "" {
+		name = "mock-agent"
+	}
+	card := &registry.AgentCard{
+		Name:               name,
+		Description:        a.scenario.Description,
+		ProtocolVersion:    "1.0",
+		Capabilities:       &registry.AgentCapabilities{Streaming: true},
+		DefaultInputModes:  []string{"text/plain"},
+		DefaultOutputModes: []string{"text/plain"},
+	}
+	for _, id := range slices.Sorted(maps.Keys(a.scenario.Skills)) {
+		if id == DefaultSkill {
+			continue
+		}
+		card.Skills = append(card.Skills, &registry.AgentSkill{
+			Id:          id,
+			Name:        id,
+			Description: a.scenario.Skills[id].Description,
+		})
+	}
+	return card
+}
+
+// StreamTask plays the script of the skill a task is addressed to. A TaskStart with
+// the ID of a task waiting for input answers it; so does a TaskStart without one on
+// the stream of such a task.
+func (a *Agent) StreamTask(stream mesh.A2AMeshService_StreamTaskServer) error {
+	md, _ := metadata.FromIncomingContext(stream.Context())
+
+	var waiting string
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		req := event.GetTaskStart().GetRequest()
+		if req == nil {
+			continue
+		}
+
+		taskID := req.TaskId
+		if taskID == "" {
+			taskID = waiting
+		}
+		r, found := a.resume(taskID), true
+		if r == nil {
+			r, found = a.start(md, req)
+		}
+		r.input = text(req.Message)
+		if !found {
+			log.Printf("No scenario for skill %q", r.skill)
+			return send(stream, &mesh.TaskStatusUpdate{
+				TaskId:  r.taskID,
+				Status:  mesh.Task_REJECTED,
+				Message: "no scenario for skill " + r.skill,
+			})
+		}
+
+		paused, err := a.play(stream, r)
+		if err != nil || !paused {
+			return err
+		}
+		waiting = r.taskID
+	}
+}
+
+// start creates the run of a new task and reports whether a script was found for it.
+func (a *Agent) start(md metadata.MD, req *mesh.TaskSendRequest) (*run, bool) {
+	r := &run{taskID: req.TaskId}
+	if r.taskID == "" {
+		r.taskID = "mock-" + uuid.NewString()
+	}
+
+	targets := slices.Concat(md.Get("x-target-skill"), []string{req.TargetAgentId})
+	for _, target := range append(targets, DefaultSkill) {
+		if script, ok := a.scenario.Skills[target]; ok && target != "" {
+			r.skill, r.steps = target, script.Steps
+			return r, true
+		}
+	}
+	r.skill = strings.Join(slices.DeleteFunc(targets, func(t string) bool { return t == "" }), ", ")
+	return r, false
+}
+
+// play runs the steps of r until the task ends or asks for input, and reports
+// whether it is waiting for input.
+func (a *Agent) play(stream mesh.A2AMeshService_StreamTaskServer, r *run) (bool, error) {
+	ctx := stream.Context()
+	for r.next < len(r.steps) {
+		step := r.steps[r.next]
+		r.next++
+
+		if err := sleep(ctx, step.Delay); err != nil {
+			return false, err
+		}
+
+		switch {
+		case step.Status != "":
+			last := mesh.Task_Status(mesh.Task_Status_value[step.Status])
+			if err := send(stream, &mesh.TaskStatusUpdate{TaskId: r.taskID, Status: last, Message: step.Message}); err != nil {
+				return false, err
+			}
+			if lifecycle.IsTerminal(last) {
+				return false, nil
+			}
+		case step.Artifact != nil:
+			if err := sendArtifact(stream, r, step.Artifact); err != nil {
+				return false, err
+			}
+		case step.Input != "":
+			a.pause(r)
+			return true, send(stream, &mesh.TaskStatusUpdate{TaskId: r.taskID, Status: mesh.Task_INPUT_REQUIRED, Message: step.Input})
+		case step.Fail != nil:
+			return false, send(stream, &mesh.TaskStatusUpdate{
+				TaskId:  r.taskID,
+				Status:  mesh.Task_FAILED,
+				Message: step.Fail.Message,
+				Error: &mesh.TaskError{
+					Code:      step.Fail.Code,
+					Message:   step.Fail.Message,
+					Retryable: step.Fail.Retryable,
+				},
+			})
+		case step.Disconnect:
+			return false, status.Error(codes.Unavailable, "mock agent disconnected")
+		}
+	}
+
+	return false, send(stream, &mesh.TaskStatusUpdate{TaskId: r.taskID, Status: mesh.Task_COMPLETED})
+}
+
+func (a *Agent) pause(r *run) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.paused[r.taskID] = r
+}
+
+// resume removes taskID from the paused tasks and returns its run, or nil.
+func (a *Agent) resume(taskID string) *run {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	r := a.paused[taskID]
+	delete(a.paused, taskID)
+	return r
+}
+
+func send(stream mesh.A2AMeshService_StreamTaskServer, update *mesh.TaskStatusUpdate) error {
+	return stream.Send(&mesh.StreamEvent{Event: &mesh.StreamEvent_StatusUpdate{StatusUpdate: update}})
+}
+
+func sendArtifact(stream mesh.A2AMeshService_StreamTaskServer, r *run, art *Artifact) error {
+	artifact := &mesh.Artifact{Id: uuid.NewString(), Name: art.Name, MimeType: art.MimeType}
+	if art.URI != "" {
+		artifact.Content = &mesh.Artifact_Uri{Uri: art.URI}
+	} else {
+		artifact.Content = &mesh.Artifact_Bytes{Bytes: []byte(strings.ReplaceAll(art.Text, "{input}", r.input))}
+		if artifact.MimeType == "" {
+			artifact.MimeType = "text/plain"
+		}
+	}
+	return stream.Send(&mesh.StreamEvent{Event: &mesh.StreamEvent_ArtifactUpdate{ArtifactUpdate: &mesh.TaskArtifactUpdate{
+		TaskId:   r.taskID,
+		Artifact: artifact,
+	}}})
+}
+
+// text returns the text parts of msg.
+func text(msg *mesh.Message) string {
+	var b strings.Builder
+	for _, part := range msg.GetParts() {
+		b.WriteString(part.GetTextPart())
+	}
+	return b.String()
+}
+
+// sleep waits for d unless ctx ends first.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,139 @@
+// Package mockagent is a mesh agent that plays scripted scenarios, to test sidecars
+// and clients against agents that stream, ask for input, fail or disconnect.
+package mockagent
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/lifecycle"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultSkill is the scenario key played for tasks that name no scripted skill.
+const DefaultSkill = "default"
+
+// Scenario scripts how a mock agent answers tasks, per skill.
+type Scenario struct {
+	// Name and Description describe the agent in its generated card.
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	// Skills maps skill IDs to the steps played for tasks addressed to them, by
+	// x-target-skill or target_agent_id. DefaultSkill is played for the others.
+	Skills map[string]Script `yaml:"skills"`
+}
+
+// Script is the steps played for a task.
+type Script struct {
+	Description string `yaml:"description"`
+	Steps       []Step `yaml:"steps"`
+}
+
+// Step is one action of a script. After its Delay, a step does exactly one of:
+// send a status update, send an artifact, ask for input, fail or disconnect.
+// A terminal status, a failure or a disconnect ends the script; a script that ends
+// without a terminal status completes the task.
+type Step struct {
+	// Delay is waited before the step.
+	Delay time.Duration `yaml:"delay"`
+	// Status sends a status update with Message, e.g. WORKING. Use Input to ask for input.
+	Status  string `yaml:"status"`
+	Message string `yaml:"message"`
+	// Artifact sends an artifact.
+	Artifact *Artifact `yaml:"artifact"`
+	// Input sends INPUT_REQUIRED with this question and waits for the answer,
+	// on the same stream or a new one carrying the task ID.
+	Input string `yaml:"input"`
+	// Fail ends the task with FAILED and this error.
+	Fail *Failure `yaml:"fail"`
+	// Disconnect aborts the stream without a final status.
+	Disconnect bool `yaml:"disconnect"`
+}
+
+// Artifact is an artifact sent by a step. In Text, "{input}" is replaced by the text
+// of the last message received for the task.
+type Artifact struct {
+	Name     string `yaml:"name"`
+	MimeType string `yaml:"mimeType"`
+	Text     string `yaml:"text"`
+	URI      string `yaml:"uri"`
+}
+
+// Failure is the error of a failed task.
+type Failure struct {
+	// Code is a gRPC code name, e.g. "Unavailable".
+	Code      string `yaml:"code"`
+	Message   string `yaml:"message"`
+	Retryable bool   `yaml:"retryable"`
+}
+
+// LoadScenario reads a scenario from a YAML or JSON file.
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %w", err)
+	}
+	var sc Scenario
+	if err := yaml.Unmarshal(data, &sc); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario %s: %w", path, err)
+	}
+	if err := sc.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+	return &sc, nil
+}
+
+// Validate checks that every step does one thing, names known statuses and is
+// reachable: no step follows one that ends the task.
+func (sc *Scenario) Validate() error {
+	if len(sc.Skills) == 0 {
+		return errors.New("no skills")
+	}
+	for skill, script := range sc.Skills {
+		for i, step := range script.Steps {
+			if err := step.validate(); err != nil {
+				return fmt.Errorf("skill %s, step %d: %w", skill, i+1, err)
+			}
+			if step.ends() && i < len(script.Steps)-1 {
+				return fmt.Errorf("skill %s, step %d: steps follow the end of the task", skill, i+2)
+			}
+		}
+	}
+	return nil
+}
+
+// ends reports whether the task ends with the step.
+func (s Step) ends() bool {
+	return s.Fail != nil || s.Disconnect || lifecycle.IsTerminal(mesh.Task_Status(mesh.Task_Status_value[s.Status]))
+}
+
+func (s Step) validate() error {
+	actions := 0
+	for _, set := range []bool{s.Status != "", s.Artifact != nil, s.Input != "", s.Fail != nil, s.Disconnect} {
+		if set {
+			actions++
+		}
+	}
+	if actions > 1 {
+		return errors.New("a step must do one thing")
+	}
+	if actions == 0 && s.Delay == 0 {
+		return errors.New("empty step")
+	}
+	if s.Status != "" {
+		status, ok := mesh.Task_Status_value[s.Status]
+		if !ok {
+			return fmt.Errorf("unknown status %q", s.Status)
+		}
+		if mesh.Task_Status(status) == mesh.Task_INPUT_REQUIRED {
+			return errors.New(`use "input" to ask for input`)
+		}
+	}
+	if s.Artifact != nil && s.Artifact.Text != "" && s.Artifact.URI != "" {
+		return errors.New("an artifact has either text or a URI")
+	}
+	return nil
+}
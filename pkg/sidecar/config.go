@@ -1,21 +1,286 @@
 package sidecar
 
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
 // Config holds the configuration for the Sidecar.
 type Config struct {
 	// AgentID is the identity of the local agent.
-	AgentID string
+	AgentID string `yaml:"agentId"`
 	// RegistryURL is the address of the central Registry.
-	RegistryURL string
+	RegistryURL string `yaml:"registryUrl"`
+	// RegistryCAFile enables TLS towards the Registry when set. The sidecar presents
+	// CertFile/KeyFile as its client certificate, so its Common Name becomes its registry principal.
+	RegistryCAFile string `yaml:"registryCaFile,omitempty"`
+	// RegistryServerName overrides the name used to verify the Registry's certificate.
+	// Defaults to the host part of RegistryURL.
+	RegistryServerName string `yaml:"registryServerName,omitempty"`
 	// LocalPort is the port for the local agent to connect to (Plaintext gRPC).
-	LocalPort int
+	LocalPort int `yaml:"localPort"`
 	// ExternalPort is the port for other sidecars to connect to (mTLS gRPC).
-	ExternalPort int
+	ExternalPort int `yaml:"externalPort"`
 	// CertFile is the path to the certificate file for mTLS.
-	CertFile string
+	CertFile string `yaml:"certFile"`
 	// KeyFile is the path to the key file for mTLS.
-	KeyFile string
+	KeyFile string `yaml:"keyFile"`
 	// CAFile is the path to the CA certificate file for mTLS.
-	CAFile string
-	// AppPort is the port where the local agent application is running.
-	AppPort int
+	CAFile string `yaml:"caFile"`
+	// AppPort is the port where the local agent application is running. Zero means the
+	// sidecar has no local agent and only sends tasks.
+	AppPort int `yaml:"appPort"`
+	// AgentCardFile is a JSON file holding the local agent's AgentCard, used for self-registration.
+	AgentCardFile string `yaml:"agentCardFile,omitempty"`
+	// AgentCardURL is fetched for the AgentCard when AgentCardFile is not set
+	// (e.g. http://localhost:8080/.well-known/agent-card.json).
+	AgentCardURL string `yaml:"agentCardUrl,omitempty"`
+	// AdvertiseAddr is the host:port registered for other sidecars to reach the external listener.
+	// Defaults to localhost:ExternalPort.
+	AdvertiseAddr string `yaml:"advertiseAddr,omitempty"`
+	// HeartbeatInterval is how often the registry is told the local agent is alive.
+	// Defaults to 30s when zero.
+	HeartbeatInterval time.Duration `yaml:"heartbeatInterval,omitempty"`
+	// HealthCheckInterval is how often the local agent and certificates are probed.
+	// Defaults to 10s when zero.
+	HealthCheckInterval time.Duration `yaml:"healthCheckInterval,omitempty"`
+	// TaskStoreFile persists the task store to a JSON file. Tasks are kept in memory only when empty.
+	TaskStoreFile string `yaml:"taskStoreFile,omitempty"`
+	// TaskTTL is how long a task is kept after its last update.
+	// Defaults to 1h when zero.
+	TaskTTL time.Duration `yaml:"taskTtl,omitempty"`
+	// ConnIdleTimeout is how long a connection to a Remote Sidecar is kept open without use.
+	// Defaults to 5m when zero.
+	ConnIdleTimeout time.Duration `yaml:"connIdleTimeout,omitempty"`
+
+	// LocalListener and ExternalListener, when set, are served instead of listening on
+	// LocalPort and ExternalPort, e.g. listeners on ephemeral ports in tests. Run closes them.
+	LocalListener    net.Listener `yaml:"-"`
+	ExternalListener net.Listener `yaml:"-"`
+
+	// PrintConfig reports whether --print-config was passed.
+	PrintConfig bool `yaml:"-"`
+}
+
+// DefaultConfig returns the configuration matching the local development setup.
+func DefaultConfig() Config {
+	return Config{
+		AgentID:             "agent-1",
+		RegistryURL:         "localhost:50051",
+		LocalPort:           50052,
+		ExternalPort:        50053,
+		AppPort:             50054,
+		CertFile:            "certs/server-cert.pem",
+		KeyFile:             "certs/server-key.pem",
+		CAFile:              "certs/ca-cert.pem",
+		HeartbeatInterval:   defaultHeartbeatInterval,
+		HealthCheckInterval: defaultHealthCheckInterval,
+		TaskTTL:             defaultTaskTTL,
+		ConnIdleTimeout:     defaultConnIdleTimeout,
+	}
+}
+
+// Validate checks the configuration for missing values, port collisions and missing certificate files.
+func (c Config) Validate() error {
+	var errs []error
+
+	if c.AgentID == "" {
+		errs = append(errs, errors.New("agentId is required"))
+	}
+	if c.RegistryURL == "" {
+		errs = append(errs, errors.New("registryUrl is required"))
+	}
+
+	ports := []struct {
+		name     string
+		port     int
+		required bool
+	}{
+		{"localPort", c.LocalPort, true},
+		{"externalPort", c.ExternalPort, true},
+		{"appPort", c.AppPort, false},
+	}
+	seen := make(map[int]string)
+	for _, p := range ports {
+		if p.port == 0 && !p.required {
+			continue
+		}
+		if p.port <= 0 || p.port > 65535 {
+			errs = append(errs, fmt.Errorf("%s %d out of range", p.name, p.port))
+			continue
+		}
+		if other, ok := seen[p.port]; ok {
+			errs = append(errs, fmt.Errorf("%s and %s both use port %d", other, p.name, p.port))
+			continue
+		}
+		seen[p.port] = p.name
+	}
+
+	files := []struct {
+		name     string
+		path     string
+		required bool
+	}{
+		{"certFile", c.CertFile, true},
+		{"keyFile", c.KeyFile, true},
+		{"caFile", c.CAFile, true},
+		{"registryCaFile", c.RegistryCAFile, false},
+		{"agentCardFile", c.AgentCardFile, false},
+	}
+	for _, f := range files {
+		if f.path == "" {
+			if f.required {
+				errs = append(errs, fmt.Errorf("%s is required", f.name))
+			}
+			continue
+		}
+		if _, err := os.Stat(f.path); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f.name, err))
+		}
+	}
+
+	if c.AgentCardFile != "" && c.AgentCardURL != "" {
+		errs = append(errs, errors.New("agentCardFile and agentCardUrl are mutually exclusive"))
+	}
+
+	if c.HeartbeatInterval < 0 {
+		errs = append(errs, errors.New("heartbeatInterval must not be negative"))
+	}
+	if c.HealthCheckInterval < 0 {
+		errs = append(errs, errors.New("healthCheckInterval must not be negative"))
+	}
+	if c.TaskTTL < 0 {
+		errs = append(errs, errors.New("taskTtl must not be negative"))
+	}
+	if c.ConnIdleTimeout < 0 {
+		errs = append(errs, errors.New("connIdleTimeout must not be negative"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// LoadConfig merges defaults, an optional YAML file, AGENTMESH_* environment variables
+// and flags (in increasing order of precedence) into a Config, and validates it.
+// The merged Config is returned along with a validation error, so --print-config
+// can show it. Asking for --help returns flag.ErrHelp.
+func LoadConfig(args []string) (Config, error) {
+	cfg := DefaultConfig()
+
+	// Flags are parsed twice: first to find the config file, then over the file and
+	// environment values, so that the flags given win.
+	scratch := cfg
+	fs, configFile := newFlagSet(&scratch)
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	if *configFile != "" {
+		data, err := os.ReadFile(*configFile)
+		if err != nil {
+			return cfg, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return cfg, fmt.Errorf("failed to parse config file %s: %w", *configFile, err)
+		}
+	}
+
+	if err := applyEnv(&cfg); err != nil {
+		return cfg, err
+	}
+
+	fs, _ = newFlagSet(&cfg)
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// newFlagSet binds the command-line flags to the fields of cfg with their current
+// values as defaults. The returned string holds the --config path.
+func newFlagSet(cfg *Config) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet("sidecar", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("AGENTMESH_CONFIG"), "path to a YAML configuration file")
+	fs.BoolVar(&cfg.PrintConfig, "print-config", cfg.PrintConfig, "print the effective configuration and exit")
+	fs.StringVar(&cfg.AgentID, "agent-id", cfg.AgentID, "identity of the local agent")
+	fs.StringVar(&cfg.RegistryURL, "registry-url", cfg.RegistryURL, "address of the registry gRPC endpoint")
+	fs.StringVar(&cfg.RegistryCAFile, "registry-ca", cfg.RegistryCAFile, "CA file enabling TLS towards the registry")
+	fs.StringVar(&cfg.RegistryServerName, "registry-server-name", cfg.RegistryServerName, "server name to verify the registry certificate against")
+	fs.IntVar(&cfg.LocalPort, "local-port", cfg.LocalPort, "plaintext port for the local agent")
+	fs.IntVar(&cfg.ExternalPort, "external-port", cfg.ExternalPort, "mTLS port for other sidecars")
+	fs.IntVar(&cfg.AppPort, "app-port", cfg.AppPort, "port of the local agent application")
+	fs.StringVar(&cfg.CertFile, "cert", cfg.CertFile, "mTLS certificate file")
+	fs.StringVar(&cfg.KeyFile, "key", cfg.KeyFile, "mTLS private key file")
+	fs.StringVar(&cfg.CAFile, "ca", cfg.CAFile, "mTLS CA certificate file")
+	fs.StringVar(&cfg.AgentCardFile, "agent-card", cfg.AgentCardFile, "JSON file with the local agent's card, enables self-registration")
+	fs.StringVar(&cfg.AgentCardURL, "agent-card-url", cfg.AgentCardURL, "URL serving the local agent's card, enables self-registration")
+	fs.StringVar(&cfg.AdvertiseAddr, "advertise-addr", cfg.AdvertiseAddr, "host:port other sidecars use to reach this one")
+	fs.DurationVar(&cfg.HeartbeatInterval, "heartbeat-interval", cfg.HeartbeatInterval, "registry heartbeat interval")
+	fs.DurationVar(&cfg.HealthCheckInterval, "health-interval", cfg.HealthCheckInterval, "health probe interval")
+	fs.StringVar(&cfg.TaskStoreFile, "task-store", cfg.TaskStoreFile, "JSON file persisting known tasks (in memory when empty)")
+	fs.DurationVar(&cfg.TaskTTL, "task-ttl", cfg.TaskTTL, "how long tasks are kept after their last update")
+	fs.DurationVar(&cfg.ConnIdleTimeout, "conn-idle-timeout", cfg.ConnIdleTimeout, "how long unused connections to remote sidecars are kept open")
+	return fs, configFile
+}
+
+// applyEnv overlays the AGENTMESH_* environment variables.
+func applyEnv(cfg *Config) error {
+	strs := map[string]*string{
+		"AGENTMESH_AGENT_ID":             &cfg.AgentID,
+		"AGENTMESH_REGISTRY_URL":         &cfg.RegistryURL,
+		"AGENTMESH_REGISTRY_CA_FILE":     &cfg.RegistryCAFile,
+		"AGENTMESH_REGISTRY_SERVER_NAME": &cfg.RegistryServerName,
+		"AGENTMESH_CERT_FILE":            &cfg.CertFile,
+		"AGENTMESH_KEY_FILE":             &cfg.KeyFile,
+		"AGENTMESH_CA_FILE":              &cfg.CAFile,
+		"AGENTMESH_AGENT_CARD_FILE":      &cfg.AgentCardFile,
+		"AGENTMESH_AGENT_CARD_URL":       &cfg.AgentCardURL,
+		"AGENTMESH_ADVERTISE_ADDR":       &cfg.AdvertiseAddr,
+		"AGENTMESH_TASK_STORE_FILE":      &cfg.TaskStoreFile,
+	}
+	for key, dst := range strs {
+		if v := os.Getenv(key); v != "" {
+			*dst = v
+		}
+	}
+
+	ints := map[string]*int{
+		"AGENTMESH_LOCAL_PORT":    &cfg.LocalPort,
+		"AGENTMESH_EXTERNAL_PORT": &cfg.ExternalPort,
+		"AGENTMESH_APP_PORT":      &cfg.AppPort,
+	}
+	for key, dst := range ints {
+		if v := os.Getenv(key); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", key, err)
+			}
+			*dst = n
+		}
+	}
+
+	durations := map[string]*time.Duration{
+		"AGENTMESH_HEARTBEAT_INTERVAL": &cfg.HeartbeatInterval,
+		"AGENTMESH_HEALTH_INTERVAL":    &cfg.HealthCheckInterval,
+		"AGENTMESH_TASK_TTL":           &cfg.TaskTTL,
+		"AGENTMESH_CONN_IDLE_TIMEOUT":  &cfg.ConnIdleTimeout,
+	}
+	for key, dst := range durations {
+		if v := os.Getenv(key); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", key, err)
+			}
+			*dst = d
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,317 @@
+package sidecar
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// minCompactEntries is the log size below which the store file is never compacted.
+const minCompactEntries = 1000
+
+// FileTaskStore is a MemoryTaskStore backed by an append-only log, so known tasks
+// survive a sidecar restart. Every change appends a JSON line to the file: the new
+// state of the task, without the history, artifacts and transitions it already had
+// when the change only appended to them. The log is compacted to one line per task
+// once it holds more than twice as many lines as tasks, and after expiry.
+type FileTaskStore struct {
+	*MemoryTaskStore
+	path string
+
+	// writeMu serializes changes so the log holds them in the order they were made.
+	// It guards file, the open log, and entries, the number of lines in it.
+	writeMu sync.Mutex
+	file    *os.File
+	entries int
+}
+
+// fileEntry is a line of the log: the state of a task after a change.
+type fileEntry struct {
+	// Delta marks a line whose Task holds only the messages and artifacts the change
+	// added to the history and artifacts, and whose Transitions only the new ones.
+	Delta       bool               `json:"delta,omitempty"`
+	Task        json.RawMessage    `json:"task"`
+	Direction   Direction          `json:"direction"`
+	Peer        string             `json:"peer,omitempty"`
+	Transitions []StatusTransition `json:"transitions,omitempty"`
+	CreatedAt   time.Time          `json:"createdAt"`
+	UpdatedAt   time.Time          `json:"updatedAt"`
+}
+
+// NewFileTaskStore opens the store at path, loading any tasks it already holds.
+func NewFileTaskStore(path string) (*FileTaskStore, error) {
+	store := &FileTaskStore{MemoryTaskStore: NewMemoryTaskStore(), path: path}
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+
+	// Start from a compacted log, which also drops a line cut off by a crash.
+	if err := store.compact(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// load replays the log into memory. A line that cannot be parsed, e.g. the last one
+// cut off by a crash, is skipped with a warning.
+func (f *FileTaskStore) load() error {
+	file, err := os.Open(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read task store: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 64<<20)
+	for line := 1; scanner.Scan(); line++ {
+		record, delta, err := decodeEntry(scanner.Bytes())
+		if err == nil && delta {
+			err = f.applyDelta(record)
+		} else if err == nil {
+			f.tasks[record.Key()] = record
+		}
+		if err != nil {
+			log.Printf("Skipping line %d of the task store %s: %v", line, f.path, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read task store: %w", err)
+	}
+	return nil
+}
+
+// applyDelta folds a delta line into the task it changes.
+func (f *FileTaskStore) applyDelta(delta *TaskRecord) error {
+	record, ok := f.tasks[delta.Key()]
+	if !ok {
+		return fmt.Errorf("change to unknown task %s", delta.Task.Id)
+	}
+	record.Task.SessionId = delta.Task.SessionId
+	record.Task.Status = delta.Task.Status
+	record.Task.Error = delta.Task.Error
+	record.Task.History = append(record.Task.History, delta.Task.History...)
+	record.Task.Artifacts = append(record.Task.Artifacts, delta.Task.Artifacts...)
+	record.Transitions = append(record.Transitions, delta.Transitions...)
+	record.UpdatedAt = delta.UpdatedAt
+	return nil
+}
+
+func (f *FileTaskStore) Update(ctx context.Context, key TaskKey, fn func(*TaskRecord)) (*TaskRecord, error) {
+	f.writeMu.Lock()
+	defer f.writeMu.Unlock()
+
+	prev := f.snapshot(key)
+	record, err := f.MemoryTaskStore.Update(ctx, key, fn)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.append(prev, record); err != nil {
+		return nil, err
+	}
+
+	f.mu.RLock()
+	tasks := len(f.tasks)
+	f.mu.RUnlock()
+	if f.entries > minCompactEntries && f.entries > 2*tasks {
+		return record, f.compact()
+	}
+	return record, nil
+}
+
+func (f *FileTaskStore) Expire(ctx context.Context, cutoff time.Time) (int, error) {
+	f.writeMu.Lock()
+	defer f.writeMu.Unlock()
+
+	removed, err := f.MemoryTaskStore.Expire(ctx, cutoff)
+	if err != nil || removed == 0 {
+		return removed, err
+	}
+	return removed, f.compact()
+}
+
+// Close closes the log.
+func (f *FileTaskStore) Close() error {
+	f.writeMu.Lock()
+	defer f.writeMu.Unlock()
+	return f.file.Close()
+}
+
+// snapshot returns the record for key as far as appendedTo and encodeDelta need it,
+// without copying the task, or nil if there is none.
+func (f *FileTaskStore) snapshot(key TaskKey) *TaskRecord {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	record, ok := f.tasks[key]
+	if !ok {
+		return nil
+	}
+	return &TaskRecord{
+		Task: &mesh.Task{
+			History:   slices.Clip(record.Task.History),
+			Artifacts: slices.Clip(record.Task.Artifacts),
+		},
+		Transitions: slices.Clip(record.Transitions),
+		CreatedAt:   record.CreatedAt,
+	}
+}
+
+// append writes the change from prev, nil for a new task, to record to the end of
+// the log. Callers hold writeMu.
+func (f *FileTaskStore) append(prev, record *TaskRecord) error {
+	var (
+		line []byte
+		err  error
+	)
+	if prev != nil && appendedTo(prev, record) {
+		line, err = encodeDelta(prev, record)
+	} else {
+		line, err = encodeEntry(record)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := f.file.Write(line); err != nil {
+		return fmt.Errorf("failed to write task store: %w", err)
+	}
+	f.entries++
+	return nil
+}
+
+// compact replaces the log with one line per known task, written to a temporary
+// file renamed over the store, and reopens it for appending. Callers hold writeMu
+// unless the store is not shared yet.
+func (f *FileTaskStore) compact() error {
+	f.mu.RLock()
+	var data []byte
+	for _, record := range f.tasks {
+		line, err := encodeEntry(record)
+		if err != nil {
+			f.mu.RUnlock()
+			return err
+		}
+		data = append(data, line...)
+	}
+	entries := len(f.tasks)
+	f.mu.RUnlock()
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write task store: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write task store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write task store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("failed to write task store: %w", err)
+	}
+
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open task store: %w", err)
+	}
+	if f.file != nil {
+		f.file.Close()
+	}
+	f.file, f.entries = file, entries
+	return nil
+}
+
+// decodeEntry parses a log line and reports whether it is a delta.
+func decodeEntry(line []byte) (*TaskRecord, bool, error) {
+	var entry fileEntry
+	if err := json.Unmarshal(line, &entry); err != nil {
+		return nil, false, err
+	}
+	task := &mesh.Task{}
+	if err := protojson.Unmarshal(entry.Task, task); err != nil {
+		return nil, false, err
+	}
+	return &TaskRecord{
+		Task:        task,
+		Direction:   entry.Direction,
+		Peer:        entry.Peer,
+		Transitions: entry.Transitions,
+		CreatedAt:   entry.CreatedAt,
+		UpdatedAt:   entry.UpdatedAt,
+	}, entry.Delta, nil
+}
+
+// encodeEntry returns the log line holding the whole of record.
+func encodeEntry(record *TaskRecord) ([]byte, error) {
+	return encodeLine(record, false)
+}
+
+// encodeDelta returns the log line for a change from prev to record that only
+// appended to the history, artifacts and transitions.
+func encodeDelta(prev, record *TaskRecord) ([]byte, error) {
+	delta := *record
+	delta.Task = &mesh.Task{
+		Id:        record.Task.Id,
+		SessionId: record.Task.SessionId,
+		Status:    record.Task.Status,
+		Error:     record.Task.Error,
+		History:   record.Task.History[len(prev.Task.History):],
+		Artifacts: record.Task.Artifacts[len(prev.Task.Artifacts):],
+	}
+	delta.Transitions = record.Transitions[len(prev.Transitions):]
+	return encodeLine(&delta, true)
+}
+
+func encodeLine(record *TaskRecord, delta bool) ([]byte, error) {
+	task, err := protojson.Marshal(record.Task)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode task %s: %w", record.Task.Id, err)
+	}
+	line, err := json.Marshal(fileEntry{
+		Delta:       delta,
+		Task:        task,
+		Direction:   record.Direction,
+		Peer:        record.Peer,
+		Transitions: record.Transitions,
+		CreatedAt:   record.CreatedAt,
+		UpdatedAt:   record.UpdatedAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode task %s: %w", record.Task.Id, err)
+	}
+	return append(line, '\n'), nil
+}
+
+// appendedTo reports whether record is prev with messages, artifacts and transitions
+// appended. The task observer only ever appends to them, or starts the task over
+// with a new CreatedAt, so only the last element prev had is compared.
+func appendedTo(prev, record *TaskRecord) bool {
+	n := len(prev.Transitions)
+	return record.CreatedAt.Equal(prev.CreatedAt) &&
+		hasPrefix(record.Task.History, prev.Task.History) &&
+		hasPrefix(record.Task.Artifacts, prev.Task.Artifacts) &&
+		len(record.Transitions) >= n &&
+		(n == 0 || record.Transitions[n-1].Status == prev.Transitions[n-1].Status && record.Transitions[n-1].At.Equal(prev.Transitions[n-1].At))
+}
+
+// hasPrefix reports whether s is at least as long as prefix and ends the prefix
+// with the same element.
+func hasPrefix[M proto.Message](s, prefix []M) bool {
+	n := len(prefix)
+	return len(s) >= n && (n == 0 || proto.Equal(s[n-1], prefix[n-1]))
+}
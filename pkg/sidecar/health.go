@@ -0,0 +1,53 @@
+package sidecar
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/meshconn"
+)
+
+const defaultHealthCheckInterval = 10 * time.Second
+
+// checkHealth returns an error if the sidecar cannot currently proxy tasks:
+// either the local agent on AppPort is unreachable or the mTLS certificate is not valid now.
+// A sidecar without an AppPort has no local agent to check.
+func (s *Server) checkHealth(ctx context.Context) error {
+	if s.config.AppPort != 0 {
+		dialer := net.Dialer{Timeout: time.Second}
+		conn, err := dialer.DialContext(ctx, "tcp", fmt.Sprintf("localhost:%d", s.config.AppPort))
+		if err != nil {
+			return fmt.Errorf("local agent unreachable on port %d: %w", s.config.AppPort, err)
+		}
+		conn.Close()
+	}
+
+	cert, err := tls.LoadX509KeyPair(s.config.CertFile, s.config.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate: %w", err)
+	}
+	now := time.Now()
+	if now.After(cert.Leaf.NotAfter) {
+		return fmt.Errorf("certificate %s expired at %s", s.config.CertFile, cert.Leaf.NotAfter)
+	}
+	if now.Before(cert.Leaf.NotBefore) {
+		return fmt.Errorf("certificate %s not valid until %s", s.config.CertFile, cert.Leaf.NotBefore)
+	}
+
+	return nil
+}
+
+// watchHealth periodically runs checkHealth and publishes the result via grpc.health.v1.
+// It blocks until ctx is cancelled, then marks the sidecar NOT_SERVING.
+func (s *Server) watchHealth(ctx context.Context) error {
+	interval := s.config.HealthCheckInterval
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+	meshconn.WatchHealth(ctx, s.health, mesh.A2AMeshService_ServiceDesc.ServiceName, "Sidecar", interval, s.checkHealth)
+	return nil
+}
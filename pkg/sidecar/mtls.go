@@ -7,9 +7,11 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"os"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/peer"
 )
 
@@ -43,6 +45,51 @@ func loadTLSCredentials(caFile, certFile, keyFile string) (credentials.Transport
 	return credentials.NewTLS(config), nil
 }
 
+// loadRegistryCredentials returns transport credentials for dialing the Registry.
+// Without a RegistryCAFile the connection is plaintext.
+func loadRegistryCredentials(cfg Config) (credentials.TransportCredentials, error) {
+	if cfg.RegistryCAFile == "" {
+		return insecure.NewCredentials(), nil
+	}
+
+	pemRegistryCA, err := os.ReadFile(cfg.RegistryCAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM(pemRegistryCA) {
+		return nil, fmt.Errorf("failed to add registry CA's certificate")
+	}
+
+	config := &tls.Config{
+		RootCAs:    certPool,
+		ServerName: cfg.RegistryServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	// Present our own certificate so the Registry can map us to a principal.
+	if cfg.CertFile != "" && cfg.KeyFile != "" {
+		clientCert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{clientCert}
+	}
+
+	return credentials.NewTLS(config), nil
+}
+
+// peerIdentity returns the Common Name (CN) of the peer certificate, if any.
+func peerIdentity(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.PeerCertificates) > 0 {
+			return tlsInfo.State.PeerCertificates[0].Subject.CommonName
+		}
+	}
+	return ""
+}
+
 // logPeerIdentityInterceptor extracts and logs the Common Name (CN) from the peer certificate.
 func logPeerIdentityInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 	if p, ok := peer.FromContext(ctx); ok {
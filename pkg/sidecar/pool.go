@@ -0,0 +1,118 @@
+package sidecar
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+const defaultConnIdleTimeout = 5 * time.Minute
+
+// connPool shares client connections by address, so that consecutive tasks to the
+// same Remote Sidecar reuse one mTLS connection instead of a handshake each.
+type connPool struct {
+	dial func(addr string) (*grpc.ClientConn, error)
+
+	mu    sync.Mutex
+	conns map[string]*pooledConn
+}
+
+type pooledConn struct {
+	conn *grpc.ClientConn
+	// inUse counts the callers holding the connection; it is only closed when zero.
+	inUse    int
+	lastUsed time.Time
+}
+
+func newConnPool(dial func(addr string) (*grpc.ClientConn, error)) *connPool {
+	return &connPool{dial: dial, conns: make(map[string]*pooledConn)}
+}
+
+// get returns a connection to addr and the func to call once done with it. A
+// connection that has shut down is replaced, and one that failed is told to
+// reconnect at once rather than after its backoff.
+func (p *connPool) get(addr string) (*grpc.ClientConn, func(), error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pc, ok := p.conns[addr]
+	if ok {
+		switch pc.conn.GetState() {
+		case connectivity.Shutdown:
+			ok = false
+		case connectivity.TransientFailure:
+			pc.conn.ResetConnectBackoff()
+		}
+	}
+	if !ok {
+		conn, err := p.dial(addr)
+		if err != nil {
+			return nil, nil, err
+		}
+		pc = &pooledConn{conn: conn}
+		p.conns[addr] = pc
+	}
+
+	pc.inUse++
+	release := sync.OnceFunc(func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		pc.inUse--
+		pc.lastUsed = time.Now()
+	})
+	return pc.conn, release, nil
+}
+
+// evict closes the connections nobody has used since cutoff and returns how many.
+func (p *connPool) evict(cutoff time.Time) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	evicted := 0
+	for addr, pc := range p.conns {
+		if pc.inUse == 0 && pc.lastUsed.Before(cutoff) {
+			pc.conn.Close()
+			delete(p.conns, addr)
+			evicted++
+		}
+	}
+	return evicted
+}
+
+// close closes all connections.
+func (p *connPool) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for addr, pc := range p.conns {
+		pc.conn.Close()
+		delete(p.conns, addr)
+	}
+}
+
+// evictConns periodically closes connections to Remote Sidecars that were idle
+// for longer than ConnIdleTimeout. It blocks until ctx is cancelled.
+func (s *Server) evictConns(ctx context.Context) error {
+	idle := s.config.ConnIdleTimeout
+	if idle == 0 {
+		idle = defaultConnIdleTimeout
+	}
+
+	ticker := time.NewTicker(min(idle, time.Minute))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if evicted := s.remotes.evict(time.Now().Add(-idle)); evicted > 0 {
+				log.Printf("Closed %d idle connections to remote sidecars", evicted)
+			}
+		}
+	}
+}
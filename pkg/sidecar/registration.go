@@ -0,0 +1,210 @@
+package sidecar
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	registry "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/registry"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	defaultHeartbeatInterval = 30 * time.Second
+	deregisterTimeout        = 5 * time.Second
+	// cardRetryDelay is the first delay before loading the agent card again. It
+	// doubles with each failure, up to the heartbeat interval.
+	cardRetryDelay = 250 * time.Millisecond
+)
+
+// loadAgentCard reads the local agent's card from AgentCardFile, or fetches it from AgentCardURL.
+// Both sources use the A2A JSON representation served by the registry's HTTP API.
+func (s *Server) loadAgentCard(ctx context.Context) (*registry.AgentCard, error) {
+	var data []byte
+	switch {
+	case s.config.AgentCardFile != "":
+		b, err := os.ReadFile(s.config.AgentCardFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read agent card: %w", err)
+		}
+		data = b
+	case s.config.AgentCardURL != "":
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.config.AgentCardURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch agent card: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to fetch agent card: %s", resp.Status)
+		}
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read agent card: %w", err)
+		}
+		data = b
+	default:
+		return nil, nil
+	}
+
+	card := &registry.AgentCard{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, card); err != nil {
+		return nil, fmt.Errorf("failed to parse agent card: %w", err)
+	}
+
+	// The sidecar is the agent's only network entry point: register under our
+	// AgentID and advertise the external mTLS listener as the grpc interface.
+	card.Did = s.config.AgentID
+	var ifaces []*registry.AgentInterface
+	for _, iface := range card.SupportedInterfaces {
+		if iface.ProtocolBinding != "grpc" {
+			ifaces = append(ifaces, iface)
+		}
+	}
+	card.SupportedInterfaces = append(ifaces, &registry.AgentInterface{
+		ProtocolBinding: "grpc",
+		Url:             s.advertiseAddr(),
+	})
+
+	return card, nil
+}
+
+// advertiseAddr returns the address other sidecars should dial to reach this one.
+func (s *Server) advertiseAddr() string {
+	if s.config.AdvertiseAddr != "" {
+		return s.config.AdvertiseAddr
+	}
+	if lis := s.config.ExternalListener; lis != nil {
+		return lis.Addr().String()
+	}
+	return fmt.Sprintf("localhost:%d", s.config.ExternalPort)
+}
+
+// register creates the registry entry for the local agent, updating it if it already exists.
+func (s *Server) register(ctx context.Context, card *registry.AgentCard) error {
+	_, err := s.registryClient.RegisterAgent(ctx, &registry.RegisterAgentRequest{AgentCard: card})
+	if status.Code(err) == codes.AlreadyExists {
+		// e.g. the sidecar restarted before its entry expired. Updating the card does
+		// not refresh the entry's heartbeat, so one is sent before it can expire.
+		_, err = s.registryClient.UpdateAgent(ctx, &registry.UpdateAgentRequest{
+			AgentId:   card.Did,
+			AgentCard: card,
+		})
+		if err == nil {
+			_, err = s.registryClient.Heartbeat(ctx, &registry.HeartbeatRequest{AgentId: card.Did})
+		}
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("Registered agent %s at %s", card.Did, s.advertiseAddr())
+	return nil
+}
+
+// runRegistration keeps the local agent registered for as long as ctx is alive.
+// It heartbeats every HeartbeatInterval, re-registers if the registry has lost the
+// entry, and deregisters the agent once ctx is cancelled.
+func (s *Server) runRegistration(ctx context.Context) error {
+	if s.config.AgentCardFile == "" && s.config.AgentCardURL == "" {
+		log.Println("No agent card configured, skipping self-registration")
+		return nil
+	}
+
+	interval := s.config.HeartbeatInterval
+	if interval <= 0 {
+		interval = defaultHeartbeatInterval
+	}
+
+	card := s.awaitAgentCard(ctx, interval)
+	if card == nil {
+		return nil
+	}
+
+	registered := false
+	tick := func() {
+		if !registered {
+			if err := s.register(ctx, card); err != nil {
+				log.Printf("Failed to register agent %s: %v", card.Did, err)
+				return
+			}
+			registered = true
+			return
+		}
+
+		_, err := s.registryClient.Heartbeat(ctx, &registry.HeartbeatRequest{AgentId: card.Did})
+		switch status.Code(err) {
+		case codes.OK:
+		case codes.NotFound:
+			// The registry lost our entry (restart, TTL expiry): register again.
+			log.Printf("Registry no longer knows agent %s, re-registering", card.Did)
+			registered = false
+			if err := s.register(ctx, card); err != nil {
+				log.Printf("Failed to re-register agent %s: %v", card.Did, err)
+				return
+			}
+			registered = true
+		default:
+			log.Printf("Heartbeat for agent %s failed: %v", card.Did, err)
+		}
+	}
+
+	tick()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if registered {
+				s.deregister(card.Did)
+			}
+			return nil
+		case <-ticker.C:
+			tick()
+		}
+	}
+}
+
+// awaitAgentCard loads the local agent's card, retrying with exponential backoff up
+// to maxDelay, e.g. while the agent is still starting. It returns nil once ctx is cancelled.
+func (s *Server) awaitAgentCard(ctx context.Context, maxDelay time.Duration) *registry.AgentCard {
+	delay := min(cardRetryDelay, maxDelay)
+	for {
+		card, err := s.loadAgentCard(ctx)
+		if err == nil {
+			return card
+		}
+		log.Printf("Failed to load agent card, retrying in %s: %v", delay, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+		delay = min(2*delay, maxDelay)
+	}
+}
+
+// deregister removes the agent from the registry during graceful shutdown.
+func (s *Server) deregister(agentID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), deregisterTimeout)
+	defer cancel()
+
+	if _, err := s.registryClient.DeleteAgent(ctx, &registry.DeleteAgentRequest{AgentId: agentID}); err != nil {
+		log.Printf("Failed to deregister agent %s: %v", agentID, err)
+		return
+	}
+	log.Printf("Deregistered agent %s", agentID)
+}
@@ -9,52 +9,112 @@ import (
 
 	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
 	registry "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/registry"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/meshconn"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
-	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/grpc/status"
 )
 
+// listPageSize is the number of registry entries fetched per ListAgents call.
+const listPageSize = 50
+
 // Server implements the Sidecar Proxy.
 type Server struct {
 	mesh.UnimplementedA2AMeshServiceServer
 	config         Config
 	registryClient registry.RegistryServiceClient
+	health         *health.Server
+	store          TaskStore
+	running        runningTasks
+	// remotes pools the connections to Remote Sidecars.
+	remotes *connPool
 }
 
 // NewServer creates a new Sidecar Server.
 func NewServer(cfg Config) (*Server, error) {
-	// Connect to Registry
-	// Note: Assuming insecure for registry connection for now as per prompt instructions focusing on sidecar-sidecar mTLS.
-	// In a real scenario, this might also use TLS.
-	conn, err := grpc.Dial(cfg.RegistryURL, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	// Connect to Registry (plaintext unless RegistryCAFile is set)
+	registryCreds, err := loadRegistryCredentials(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load registry credentials: %w", err)
+	}
+
+	conn, err := grpc.Dial(cfg.RegistryURL,
+		grpc.WithTransportCredentials(registryCreds),
+		telemetry.DialOption(),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to registry: %w", err)
 	}
 
-	return &Server{
+	// Tasks are kept in memory unless a store file is configured
+	var store TaskStore = NewMemoryTaskStore()
+	if cfg.TaskStoreFile != "" {
+		if store, err = NewFileTaskStore(cfg.TaskStoreFile); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &Server{
 		config:         cfg,
 		registryClient: registry.NewRegistryServiceClient(conn),
-	}, nil
+		health:         health.NewServer(),
+		store:          store,
+	}
+	s.remotes = newConnPool(s.dialRemote)
+	return s, nil
 }
 
 // Run starts the two concurrent listeners.
 func (s *Server) Run(ctx context.Context) error {
 	g, ctx := errgroup.WithContext(ctx)
 
+	// 0. Health Checks
+	g.Go(func() error {
+		return s.watchHealth(ctx)
+	})
+
+	// 0b. Self-registration and heartbeats for the local agent
+	g.Go(func() error {
+		return s.runRegistration(ctx)
+	})
+
+	// 0c. Task retention
+	g.Go(func() error {
+		return s.expireTasks(ctx)
+	})
+
+	// 0d. Idle connections to Remote Sidecars
+	g.Go(func() error {
+		return s.evictConns(ctx)
+	})
+
 	// 1. Local Listener (Plaintext, localhost)
 	g.Go(func() error {
-		lis, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", s.config.LocalPort))
-		if err != nil {
-			return fmt.Errorf("failed to listen on local port: %w", err)
+		lis := s.config.LocalListener
+		if lis == nil {
+			var err error
+			if lis, err = net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", s.config.LocalPort)); err != nil {
+				return fmt.Errorf("failed to listen on local port: %w", err)
+			}
 		}
-		log.Printf("Local listener started on localhost:%d", s.config.LocalPort)
+		log.Printf("Local listener started on %s", lis.Addr())
 
-		grpcServer := grpc.NewServer()
+		grpcServer := grpc.NewServer(
+			telemetry.ServerOption(),
+			meshconn.KeepaliveServerOption(),
+		)
 		mesh.RegisterA2AMeshServiceServer(grpcServer, s)
+		healthpb.RegisterHealthServer(grpcServer, s.health)
 
 		// Graceful shutdown on context cancellation
 		go func() {
@@ -70,23 +130,31 @@ func (s *Server) Run(ctx context.Context) error {
 
 	// 2. External Listener (mTLS, 0.0.0.0)
 	g.Go(func() error {
+		lis := s.config.ExternalListener
 		creds, err := loadTLSCredentials(s.config.CAFile, s.config.CertFile, s.config.KeyFile)
 		if err != nil {
+			if lis != nil {
+				lis.Close()
+			}
 			return fmt.Errorf("failed to load TLS credentials: %w", err)
 		}
 
-		lis, err := net.Listen("tcp", fmt.Sprintf("0.0.0.0:%d", s.config.ExternalPort))
-		if err != nil {
-			return fmt.Errorf("failed to listen on external port: %w", err)
+		if lis == nil {
+			if lis, err = net.Listen("tcp", fmt.Sprintf("0.0.0.0:%d", s.config.ExternalPort)); err != nil {
+				return fmt.Errorf("failed to listen on external port: %w", err)
+			}
 		}
-		log.Printf("External listener started on 0.0.0.0:%d", s.config.ExternalPort)
+		log.Printf("External listener started on %s", lis.Addr())
 
 		grpcServer := grpc.NewServer(
 			grpc.Creds(creds),
+			telemetry.ServerOption(),
+			meshconn.KeepaliveServerOption(),
 			grpc.UnaryInterceptor(logPeerIdentityInterceptor),
 			grpc.StreamInterceptor(streamLogPeerIdentityInterceptor),
 		)
 		mesh.RegisterA2AMeshServiceServer(grpcServer, s)
+		healthpb.RegisterHealthServer(grpcServer, s.health)
 
 		// Graceful shutdown on context cancellation
 		go func() {
@@ -100,21 +168,20 @@ func (s *Server) Run(ctx context.Context) error {
 		return nil
 	})
 
-	return g.Wait()
+	err := g.Wait()
+	// The listeners have stopped, so no task uses the pooled connections or the store any more.
+	s.remotes.close()
+	if closer, ok := s.store.(io.Closer); ok {
+		closer.Close()
+	}
+	return err
 }
 
 // StreamTask handles the bidirectional streaming of tasks.
 // It implements the logic for both Outbound (Local -> Remote) and Inbound (Remote -> Local) requests.
 func (s *Server) StreamTask(stream mesh.A2AMeshService_StreamTaskServer) error {
-	p, ok := peer.FromContext(stream.Context())
-	if !ok {
-		return fmt.Errorf("failed to get peer info")
-	}
-
 	// Check if connection has TLS info. If yes, it's from External Listener (mTLS).
-	_, hasTLS := p.AuthInfo.(credentials.TLSInfo)
-
-	if hasTLS {
+	if isInbound(stream.Context()) {
 		// Case B: Inbound Request (Remote Sidecar -> Local Agent)
 		return s.handleInbound(stream)
 	} else {
@@ -124,87 +191,64 @@ func (s *Server) StreamTask(stream mesh.A2AMeshService_StreamTaskServer) error {
 }
 
 // handleOutbound handles requests from the Local Agent intended for a Remote Agent.
-func (s *Server) handleOutbound(stream mesh.A2AMeshService_StreamTaskServer) error {
-	ctx := stream.Context()
+func (s *Server) handleOutbound(stream mesh.A2AMeshService_StreamTaskServer) (err error) {
+	ctx, span := telemetry.Tracer().Start(stream.Context(), "sidecar.outbound", trace.WithSpanKind(trace.SpanKindInternal))
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		return fmt.Errorf("missing metadata")
 	}
 
-	// 1. Discovery: Get TargetSkill from metadata
-	targetSkills := md.Get("x-target-skill")
-	if len(targetSkills) == 0 {
-		return fmt.Errorf("missing x-target-skill metadata")
+	// The first event is read before dialing: a TaskStart continuing a known
+	// task goes back to the Remote Sidecar running it.
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return nil
 	}
-	targetSkill := targetSkills[0]
-
-	// 2. Registry Lookup
-	// Note: ListAgentsRequest might need to be updated to support filtering by skill if not already.
-	// Assuming ListAgents returns all and we filter, or it supports filtering.
-	listResp, err := s.registryClient.ListAgents(ctx, &registry.ListAgentsRequest{}) // TODO: Add filter if available
 	if err != nil {
-		return fmt.Errorf("failed to list agents: %w", err)
-	}
-
-	var targetAgent *registry.AgentCard
-	for _, agent := range listResp.Agents {
-		// Simple matching logic: check if agent has the skill.
-		// Assuming AgentCard has a list of skills or similar.
-		// If not, we might just pick the first one for now as a placeholder.
-		// The prompt says "Pick the first available agent".
-		targetAgent = agent.AgentCard
-		break
-	}
-
-	if targetAgent == nil {
-		return fmt.Errorf("no agent found for skill: %s", targetSkill)
+		return err
 	}
 
-	// 3. Dial Remote Sidecar (mTLS)
-	// We need the remote sidecar's address. Assuming it's in the AgentCard.
-	// AgentCard usually has `address` or `endpoints`.
-	// Let's assume `targetAgent.Address` is the host:port.
-	// Wait, AgentCard definition in registry.proto:
-	// message AgentCard { ... repeated AgentInterface supported_interfaces = 9; ... }
-	// message AgentInterface { string protocol_binding = 1; string url = 2; }
-	// We need to find the interface with protocol_binding="grpc" (or similar).
-
-	var remoteAddr string
-	for _, iface := range targetAgent.SupportedInterfaces {
-		if iface.ProtocolBinding == "grpc" { // Assuming "grpc" is the binding name
-			remoteAddr = iface.Url
-			break
+	remoteAddr, ok := s.taskPeer(ctx, first.GetTaskStart().GetRequest().GetTaskId())
+	if !ok {
+		remoteAddr, err = s.discoverRemote(ctx, md)
+		if err != nil {
+			return err
 		}
 	}
+	span.SetAttributes(attribute.String("agentmesh.remote_addr", remoteAddr))
 
-	if remoteAddr == "" {
-		// Fallback or error
-		return fmt.Errorf("no grpc interface found for agent")
-	}
-
-	creds, err := loadTLSCredentials(s.config.CAFile, s.config.CertFile, s.config.KeyFile)
+	conn, release, err := s.remotes.get(remoteAddr)
 	if err != nil {
-		return fmt.Errorf("failed to load TLS credentials for outbound: %w", err)
+		return err
 	}
-
-	conn, err := grpc.Dial(remoteAddr, grpc.WithTransportCredentials(creds))
-	if err != nil {
-		return fmt.Errorf("failed to dial remote sidecar: %w", err)
-	}
-	defer conn.Close()
+	defer release()
 
 	remoteClient := mesh.NewA2AMeshServiceClient(conn)
 
 	// 4. Forwarding: Create bidirectional stream
-	// We need to forward metadata as well.
+	// We need to forward metadata as well. The traceparent entry is
+	// overwritten by the telemetry dial option with this hop's span.
 	outCtx := metadata.NewOutgoingContext(ctx, md)
 	remoteStream, err := remoteClient.StreamTask(outCtx)
 	if err != nil {
 		return fmt.Errorf("failed to start remote stream: %w", err)
 	}
 
-	// Pipe streams
+	// Pipe streams, recording the task as it passes through
 	errChan := make(chan error, 2)
+	obs := s.observer(Outbound, remoteAddr, cancel)
+	defer func() { obs.finish(ctx, err) }()
+
+	if err := obs.observe(ctx, first); err != nil {
+		return err
+	}
+	if err := remoteStream.Send(first); err != nil {
+		return fmt.Errorf("failed to forward task start: %w", err)
+	}
 
 	// Local -> Remote
 	go func() {
@@ -218,6 +262,10 @@ func (s *Server) handleOutbound(stream mesh.A2AMeshService_StreamTaskServer) err
 				errChan <- err
 				return
 			}
+			if err := obs.observe(ctx, msg); err != nil {
+				errChan <- err
+				return
+			}
 			if err := remoteStream.Send(msg); err != nil {
 				errChan <- err
 				return
@@ -230,12 +278,18 @@ func (s *Server) handleOutbound(stream mesh.A2AMeshService_StreamTaskServer) err
 		for {
 			msg, err := remoteStream.Recv()
 			if err == io.EOF {
+				// The remote side is done with the task
+				errChan <- nil
 				return
 			}
 			if err != nil {
 				errChan <- err
 				return
 			}
+			if err := obs.observe(ctx, msg); err != nil {
+				errChan <- err
+				return
+			}
 			if err := stream.Send(msg); err != nil {
 				errChan <- err
 				return
@@ -247,34 +301,107 @@ func (s *Server) handleOutbound(stream mesh.A2AMeshService_StreamTaskServer) err
 	case err := <-errChan:
 		return err
 	case <-ctx.Done():
-		return ctx.Err()
+		return status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// discoverRemote picks the Remote Sidecar for a new outbound task from the
+// x-target-agent or, without it, the x-target-skill metadata and returns its address.
+func (s *Server) discoverRemote(ctx context.Context, md metadata.MD) (string, error) {
+	// A caller that already knows the agent, e.g. from its own registry lookup, names it.
+	if agentIDs := md.Get("x-target-agent"); len(agentIDs) > 0 {
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("agentmesh.remote_agent", agentIDs[0]))
+		return s.agentAddress(ctx, agentIDs[0])
+	}
+
+	targetSkills := md.Get("x-target-skill")
+	if len(targetSkills) == 0 {
+		return "", fmt.Errorf("missing x-target-skill metadata")
+	}
+	targetSkill := targetSkills[0]
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.String("agentmesh.target_skill", targetSkill))
+
+	targetAgent, err := s.findSkill(ctx, targetSkill)
+	if err != nil {
+		return "", err
 	}
+	remoteAddr, err := grpcAddress(targetAgent)
+	if err != nil {
+		return "", err
+	}
+	span.SetAttributes(attribute.String("agentmesh.remote_agent", targetAgent.Did))
+	return remoteAddr, nil
+}
+
+// findSkill returns the card of the first registered agent, other than the local
+// one, that offers the skill with ID skill.
+func (s *Server) findSkill(ctx context.Context, skill string) (*registry.AgentCard, error) {
+	for offset := 0; ; {
+		resp, err := s.registryClient.ListAgents(ctx, &registry.ListAgentsRequest{
+			Limit:  listPageSize,
+			Offset: int32(offset),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list agents: %w", err)
+		}
+		for _, entry := range resp.Agents {
+			if entry.AgentId == s.config.AgentID {
+				continue
+			}
+			for _, sk := range entry.AgentCard.GetSkills() {
+				if sk.Id == skill {
+					return entry.AgentCard, nil
+				}
+			}
+		}
+
+		offset += len(resp.Agents)
+		if len(resp.Agents) == 0 || offset >= int(resp.Total) {
+			return nil, fmt.Errorf("no agent found for skill: %s", skill)
+		}
+	}
+}
+
+// agentAddress returns the address of the Remote Sidecar fronting agentID.
+func (s *Server) agentAddress(ctx context.Context, agentID string) (string, error) {
+	entry, err := s.registryClient.GetAgent(ctx, &registry.GetAgentRequest{AgentId: agentID})
+	if err != nil {
+		return "", fmt.Errorf("failed to look up agent %s: %w", agentID, err)
+	}
+	return grpcAddress(entry.AgentCard)
 }
 
 // handleInbound handles requests from a Remote Sidecar intended for the Local Agent.
-func (s *Server) handleInbound(stream mesh.A2AMeshService_StreamTaskServer) error {
+func (s *Server) handleInbound(stream mesh.A2AMeshService_StreamTaskServer) (err error) {
+	ctx, span := telemetry.Tracer().Start(stream.Context(), "sidecar.inbound", trace.WithSpanKind(trace.SpanKindInternal))
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	span.SetAttributes(attribute.Int("agentmesh.app_port", s.config.AppPort))
+
 	// Forward to Local Agent running on AppPort.
 	// Connect to Local Agent (Plaintext)
-	conn, err := grpc.Dial(fmt.Sprintf("localhost:%d", s.config.AppPort), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := s.dialLocalAgent()
 	if err != nil {
-		return fmt.Errorf("failed to connect to local agent: %w", err)
+		return err
 	}
 	defer conn.Close()
 
 	localClient := mesh.NewA2AMeshServiceClient(conn)
 
-	ctx := stream.Context()
-	// Forward metadata? Yes.
-	md, _ := metadata.FromIncomingContext(ctx)
-	outCtx := metadata.NewOutgoingContext(ctx, md)
+	// Forward metadata, including who is calling.
+	outCtx := forwardMetadata(ctx)
 
 	localStream, err := localClient.StreamTask(outCtx)
 	if err != nil {
 		return fmt.Errorf("failed to start local stream: %w", err)
 	}
 
-	// Pipe streams
+	// Pipe streams, recording the task as it passes through
 	errChan := make(chan error, 2)
+	obs := s.observer(Inbound, peerIdentity(ctx), cancel)
+	defer func() { obs.finish(ctx, err) }()
 
 	// Remote -> Local
 	go func() {
@@ -288,6 +415,10 @@ func (s *Server) handleInbound(stream mesh.A2AMeshService_StreamTaskServer) erro
 				errChan <- err
 				return
 			}
+			if err := obs.observe(ctx, msg); err != nil {
+				errChan <- err
+				return
+			}
 			if err := localStream.Send(msg); err != nil {
 				errChan <- err
 				return
@@ -300,12 +431,18 @@ func (s *Server) handleInbound(stream mesh.A2AMeshService_StreamTaskServer) erro
 		for {
 			msg, err := localStream.Recv()
 			if err == io.EOF {
+				// The local agent is done with the task
+				errChan <- nil
 				return
 			}
 			if err != nil {
 				errChan <- err
 				return
 			}
+			if err := obs.observe(ctx, msg); err != nil {
+				errChan <- err
+				return
+			}
 			if err := stream.Send(msg); err != nil {
 				errChan <- err
 				return
@@ -317,18 +454,66 @@ func (s *Server) handleInbound(stream mesh.A2AMeshService_StreamTaskServer) erro
 	case err := <-errChan:
 		return err
 	case <-ctx.Done():
-		return ctx.Err()
+		return status.FromContextError(ctx.Err()).Err()
 	}
 }
 
-func (s *Server) SendTask(ctx context.Context, req *mesh.TaskSendRequest) (*mesh.Task, error) {
-	return nil, fmt.Errorf("not implemented")
+// isInbound reports whether ctx belongs to a call on the external (mTLS) listener,
+// i.e. a Remote Sidecar calling in, as opposed to the Local Agent calling out.
+func isInbound(ctx context.Context) bool {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return false
+	}
+	_, hasTLS := p.AuthInfo.(credentials.TLSInfo)
+	return hasTLS
 }
 
-func (s *Server) GetTask(ctx context.Context, req *mesh.GetTaskRequest) (*mesh.Task, error) {
-	return nil, fmt.Errorf("not implemented")
+// grpcAddress returns the address of the sidecar fronting the agent described by card.
+func grpcAddress(card *registry.AgentCard) (string, error) {
+	for _, iface := range card.SupportedInterfaces {
+		if iface.ProtocolBinding == "grpc" {
+			return iface.Url, nil
+		}
+	}
+	return "", fmt.Errorf("no grpc interface found for agent")
 }
 
-func (s *Server) CancelTask(ctx context.Context, req *mesh.CancelTaskRequest) (*emptypb.Empty, error) {
-	return nil, fmt.Errorf("not implemented")
+// dialRemote opens an mTLS connection to a Remote Sidecar.
+func (s *Server) dialRemote(addr string) (*grpc.ClientConn, error) {
+	creds, err := loadTLSCredentials(s.config.CAFile, s.config.CertFile, s.config.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS credentials for outbound: %w", err)
+	}
+
+	conn, err := grpc.Dial(addr,
+		grpc.WithTransportCredentials(creds),
+		meshconn.KeepaliveDialOption(),
+		telemetry.DialOption(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial remote sidecar: %w", err)
+	}
+	return conn, nil
+}
+
+// dialLocalAgent opens a plaintext connection to the Local Agent on AppPort.
+func (s *Server) dialLocalAgent() (*grpc.ClientConn, error) {
+	conn, err := grpc.Dial(fmt.Sprintf("localhost:%d", s.config.AppPort),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		telemetry.DialOption(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to local agent: %w", err)
+	}
+	return conn, nil
+}
+
+// endSpan records err on the span, if any, and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
 }
@@ -0,0 +1,174 @@
+package sidecar
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+	"google.golang.org/protobuf/proto"
+)
+
+// defaultTaskTTL is how long a task is kept after its last update.
+const defaultTaskTTL = time.Hour
+
+// ErrTaskNotFound is returned by a TaskStore for unknown task IDs.
+var ErrTaskNotFound = errors.New("task not found")
+
+// Direction tells whether a task was started by the Local Agent or by a Remote Sidecar.
+type Direction string
+
+const (
+	// Outbound tasks are started by the Local Agent and run by a remote agent.
+	Outbound Direction = "outbound"
+	// Inbound tasks are started by a Remote Sidecar and run by the Local Agent.
+	Inbound Direction = "inbound"
+)
+
+// StatusTransition records when a task entered a status.
+type StatusTransition struct {
+	Status mesh.Task_Status `json:"status"`
+	At     time.Time        `json:"at"`
+}
+
+// TaskKey identifies a task in a TaskStore. Task IDs are chosen by whoever runs the
+// task, so they are only unique per peer.
+type TaskKey struct {
+	Direction Direction
+	// Peer is the address of the Remote Sidecar running an outbound task, or the
+	// identity of the Remote Sidecar that started an inbound one.
+	Peer string
+	ID   string
+}
+
+// TaskRecord is everything the sidecar remembers about a task.
+type TaskRecord struct {
+	Task        *mesh.Task
+	Direction   Direction
+	Peer        string
+	Transitions []StatusTransition
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Key returns the key of the record in its TaskStore.
+func (r *TaskRecord) Key() TaskKey {
+	return TaskKey{Direction: r.Direction, Peer: r.Peer, ID: r.Task.Id}
+}
+
+func (r *TaskRecord) clone() *TaskRecord {
+	c := *r
+	c.Task = proto.Clone(r.Task).(*mesh.Task)
+	c.Transitions = append([]StatusTransition(nil), r.Transitions...)
+	return &c
+}
+
+// TaskQuery filters the tasks returned by TaskStore.List. Zero values match everything.
+type TaskQuery struct {
+	TaskID    string
+	ContextID string
+	Status    mesh.Task_Status
+	Direction Direction
+	Limit     int
+}
+
+func (q TaskQuery) matches(r *TaskRecord) bool {
+	if q.TaskID != "" && r.Task.Id != q.TaskID {
+		return false
+	}
+	if q.ContextID != "" && r.Task.SessionId != q.ContextID {
+		return false
+	}
+	if q.Status != mesh.Task_STATUS_UNSPECIFIED && r.Task.Status != q.Status {
+		return false
+	}
+	if q.Direction != "" && r.Direction != q.Direction {
+		return false
+	}
+	return true
+}
+
+// TaskStore records the tasks seen by the sidecar in either direction.
+type TaskStore interface {
+	// Update applies fn to the record for key, creating an empty one if needed,
+	// and returns a copy of the result.
+	Update(ctx context.Context, key TaskKey, fn func(*TaskRecord)) (*TaskRecord, error)
+	// Get returns a copy of the record for key or ErrTaskNotFound.
+	Get(ctx context.Context, key TaskKey) (*TaskRecord, error)
+	// List returns the matching records, most recently updated first.
+	List(ctx context.Context, query TaskQuery) ([]*TaskRecord, error)
+	// Expire removes the records not updated since cutoff and returns how many were removed.
+	Expire(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+// MemoryTaskStore is an in-memory TaskStore.
+type MemoryTaskStore struct {
+	mu    sync.RWMutex
+	tasks map[TaskKey]*TaskRecord
+}
+
+// NewMemoryTaskStore creates an empty MemoryTaskStore.
+func NewMemoryTaskStore() *MemoryTaskStore {
+	return &MemoryTaskStore{tasks: make(map[TaskKey]*TaskRecord)}
+}
+
+func (m *MemoryTaskStore) Update(ctx context.Context, key TaskKey, fn func(*TaskRecord)) (*TaskRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	record, ok := m.tasks[key]
+	if !ok {
+		record = &TaskRecord{Task: &mesh.Task{Id: key.ID}, Direction: key.Direction, Peer: key.Peer, CreatedAt: now}
+		m.tasks[key] = record
+	}
+	fn(record)
+	record.UpdatedAt = now
+	return record.clone(), nil
+}
+
+func (m *MemoryTaskStore) Get(ctx context.Context, key TaskKey) (*TaskRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	record, ok := m.tasks[key]
+	if !ok {
+		return nil, ErrTaskNotFound
+	}
+	return record.clone(), nil
+}
+
+func (m *MemoryTaskStore) List(ctx context.Context, query TaskQuery) ([]*TaskRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var records []*TaskRecord
+	for _, record := range m.tasks {
+		if query.matches(record) {
+			records = append(records, record.clone())
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].UpdatedAt.After(records[j].UpdatedAt)
+	})
+	if query.Limit > 0 && len(records) > query.Limit {
+		records = records[:query.Limit]
+	}
+	return records, nil
+}
+
+func (m *MemoryTaskStore) Expire(ctx context.Context, cutoff time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	removed := 0
+	for key, record := range m.tasks {
+		if record.UpdatedAt.Before(cutoff) {
+			delete(m.tasks, key)
+			removed++
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,477 @@
+package sidecar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/lifecycle"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// SendTask runs a task to completion and returns the assembled Task.
+// From the Local Agent it is routed to the Remote Sidecar fronting TargetAgentId,
+// or to the one already running TaskId; from a Remote Sidecar it is run against the Local Agent.
+func (s *Server) SendTask(ctx context.Context, req *mesh.TaskSendRequest) (*mesh.Task, error) {
+	if isInbound(ctx) {
+		conn, err := s.dialLocalAgent()
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+
+		return s.runTask(forwardMetadata(ctx), mesh.NewA2AMeshServiceClient(conn), req, Inbound, peerIdentity(ctx))
+	}
+
+	remoteAddr, ok := s.taskPeer(ctx, req.TaskId)
+	if !ok {
+		if req.TargetAgentId == "" {
+			return nil, status.Error(codes.InvalidArgument, "target_agent_id is required")
+		}
+
+		var err error
+		if remoteAddr, err = s.agentAddress(ctx, req.TargetAgentId); err != nil {
+			return nil, err
+		}
+	}
+
+	conn, release, err := s.remotes.get(remoteAddr)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return s.runTask(forwardMetadata(ctx), mesh.NewA2AMeshServiceClient(conn), req, Outbound, remoteAddr)
+}
+
+// GetTask returns the state of a task. The Local Agent's outbound tasks are asked of
+// the Remote Sidecar running them; when it cannot be reached, and for other tasks,
+// the last known state in the task store is returned. Remote Sidecars only see the
+// tasks they started on the Local Agent; tasks the sidecar has not seen are looked up
+// on the Local Agent.
+func (s *Server) GetTask(ctx context.Context, req *mesh.GetTaskRequest) (*mesh.Task, error) {
+	if !isInbound(ctx) {
+		if peer, ok := s.taskPeer(ctx, req.Id); ok {
+			task, err := s.remoteTask(ctx, peer, req)
+			if status.Code(err) != codes.Unavailable {
+				return task, err
+			}
+			log.Printf("Remote sidecar %s is unreachable, returning the recorded state of task %s: %v", peer, req.Id, err)
+		}
+
+		// Task IDs are only unique per peer: the most recent task wins.
+		records, err := s.store.List(ctx, TaskQuery{TaskID: req.Id, Limit: 1})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to read task store: %v", err)
+		}
+		if len(records) == 0 {
+			return nil, status.Errorf(codes.NotFound, "unknown task: %s", req.Id)
+		}
+		return records[0].Task, nil
+	}
+
+	record, err := s.store.Get(ctx, TaskKey{Direction: Inbound, Peer: peerIdentity(ctx), ID: req.Id})
+	if err == nil {
+		return record.Task, nil
+	}
+	if !errors.Is(err, ErrTaskNotFound) {
+		return nil, status.Errorf(codes.Internal, "failed to read task store: %v", err)
+	}
+	conn, err := s.dialLocalAgent()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return mesh.NewA2AMeshServiceClient(conn).GetTask(forwardMetadata(ctx), req)
+}
+
+// remoteTask asks the Remote Sidecar at peer for the state of a task it runs.
+func (s *Server) remoteTask(ctx context.Context, peer string, req *mesh.GetTaskRequest) (*mesh.Task, error) {
+	conn, release, err := s.remotes.get(peer)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to connect to %s: %v", peer, err)
+	}
+	defer release()
+	return mesh.NewA2AMeshServiceClient(conn).GetTask(forwardMetadata(ctx), req)
+}
+
+// CancelTask forwards a cancellation to whoever runs the task. If that agent does not
+// implement CancelTask, the sidecar aborts the stream carrying the task instead.
+func (s *Server) CancelTask(ctx context.Context, req *mesh.CancelTaskRequest) (*emptypb.Empty, error) {
+	client, key, release, err := s.taskOwner(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	resp, err := client.CancelTask(forwardMetadata(ctx), req)
+	if status.Code(err) == codes.Unimplemented && s.running.cancel(key) {
+		log.Printf("Agent cannot cancel task %s, aborted its stream", req.Id)
+		return &emptypb.Empty{}, nil
+	}
+	return resp, err
+}
+
+// ListTasks lets the Local Agent query the tasks recorded by the sidecar.
+func (s *Server) ListTasks(ctx context.Context, req *mesh.ListTasksRequest) (*mesh.ListTasksResponse, error) {
+	if isInbound(ctx) {
+		return nil, status.Error(codes.PermissionDenied, "tasks can only be listed by the local agent")
+	}
+
+	records, err := s.store.List(ctx, TaskQuery{
+		ContextID: req.ContextId,
+		Status:    req.Status,
+		Limit:     int(req.Limit),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read task store: %v", err)
+	}
+
+	resp := &mesh.ListTasksResponse{}
+	for _, record := range records {
+		resp.Tasks = append(resp.Tasks, record.Task)
+	}
+	return resp, nil
+}
+
+// taskOwner returns a client for the party responsible for taskID, the key of the
+// task and the func to call once done with the client.
+func (s *Server) taskOwner(ctx context.Context, taskID string) (mesh.A2AMeshServiceClient, TaskKey, func(), error) {
+	if isInbound(ctx) {
+		conn, err := s.dialLocalAgent()
+		if err != nil {
+			return nil, TaskKey{}, nil, err
+		}
+		key := TaskKey{Direction: Inbound, Peer: peerIdentity(ctx), ID: taskID}
+		return mesh.NewA2AMeshServiceClient(conn), key, func() { conn.Close() }, nil
+	}
+
+	peer, ok := s.taskPeer(ctx, taskID)
+	if !ok {
+		return nil, TaskKey{}, nil, status.Errorf(codes.NotFound, "unknown task: %s", taskID)
+	}
+
+	conn, release, err := s.remotes.get(peer)
+	if err != nil {
+		return nil, TaskKey{}, nil, err
+	}
+	return mesh.NewA2AMeshServiceClient(conn), TaskKey{Direction: Outbound, Peer: peer, ID: taskID}, release, nil
+}
+
+// taskPeer returns the address of the Remote Sidecar running the outbound task taskID.
+// Task IDs are only unique per peer, so the most recent such task wins.
+func (s *Server) taskPeer(ctx context.Context, taskID string) (string, bool) {
+	if taskID == "" {
+		return "", false
+	}
+	records, err := s.store.List(ctx, TaskQuery{TaskID: taskID, Direction: Outbound, Limit: 1})
+	if err != nil || len(records) == 0 {
+		return "", false
+	}
+	return records[0].Peer, true
+}
+
+// expireTasks drops tasks that have not been updated within the configured TTL.
+func (s *Server) expireTasks(ctx context.Context) error {
+	ttl := s.config.TaskTTL
+	if ttl == 0 {
+		ttl = defaultTaskTTL
+	}
+
+	ticker := time.NewTicker(min(ttl, time.Minute))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			removed, err := s.store.Expire(ctx, time.Now().Add(-ttl))
+			if err != nil {
+				log.Printf("Failed to expire tasks: %v", err)
+				continue
+			}
+			if removed > 0 {
+				log.Printf("Expired %d tasks", removed)
+			}
+		}
+	}
+}
+
+// runningTasks maps the keys of tasks with an open stream through the sidecar
+// to the func aborting that stream.
+type runningTasks struct {
+	mu      sync.Mutex
+	cancels map[TaskKey]context.CancelFunc
+}
+
+func (r *runningTasks) add(key TaskKey, cancel context.CancelFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancels == nil {
+		r.cancels = make(map[TaskKey]context.CancelFunc)
+	}
+	r.cancels[key] = cancel
+}
+
+func (r *runningTasks) remove(key TaskKey) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.cancels, key)
+}
+
+// cancel aborts the stream of the task and reports whether there was one.
+func (r *runningTasks) cancel(key TaskKey) bool {
+	r.mu.Lock()
+	cancel, ok := r.cancels[key]
+	r.mu.Unlock()
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// taskObserver records the events of one task stream in the task store.
+// Store failures are logged and never interrupt the stream.
+type taskObserver struct {
+	store     TaskStore
+	running   *runningTasks
+	direction Direction
+	peer      string
+	// cancel aborts the stream; it is registered in running once the task ID is known.
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	request *mesh.TaskSendRequest
+	// key is set once the task ID is known.
+	key TaskKey
+	// recorded is set once the stream has updated the task's record.
+	recorded bool
+}
+
+func (s *Server) observer(direction Direction, peer string, cancel context.CancelFunc) *taskObserver {
+	return &taskObserver{store: s.store, running: &s.running, direction: direction, peer: peer, cancel: cancel}
+}
+
+// setTaskID records the ID assigned to the task by the agent running it. Callers hold o.mu.
+func (o *taskObserver) setTaskID(taskID string) {
+	if taskID == "" || o.key.ID != "" {
+		return
+	}
+	o.key = TaskKey{Direction: o.direction, Peer: o.peer, ID: taskID}
+	o.running.add(o.key, o.cancel)
+}
+
+// finish is called when the stream ends. A task left running because the stream
+// was canceled is recorded as CANCELED; a task waiting for input can still be
+// continued on another stream.
+func (o *taskObserver) finish(ctx context.Context, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.key.ID == "" {
+		return
+	}
+	o.running.remove(o.key)
+
+	if ctx.Err() == nil && status.Code(err) != codes.Canceled {
+		return
+	}
+	o.update(context.WithoutCancel(ctx), func(r *TaskRecord) {
+		if lifecycle.IsTerminal(r.Task.Status) || lifecycle.IsInterrupted(r.Task.Status) {
+			return
+		}
+		r.Task.Status = mesh.Task_CANCELED
+		r.Transitions = append(r.Transitions, StatusTransition{Status: mesh.Task_CANCELED, At: time.Now()})
+	})
+}
+
+// observe records event. It returns a FailedPrecondition error, without recording
+// anything, when a status update breaks the task lifecycle.
+func (o *taskObserver) observe(ctx context.Context, event *mesh.StreamEvent) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	switch e := event.Event.(type) {
+	case *mesh.StreamEvent_TaskStart:
+		if o.key.ID == "" {
+			// A TaskStart naming its task continues it on this stream.
+			if taskID := e.TaskStart.Request.GetTaskId(); taskID != "" {
+				o.setTaskID(taskID)
+			} else {
+				o.request = e.TaskStart.Request
+				return nil
+			}
+		}
+		// A further turn on a task that is already known.
+		if msg := e.TaskStart.Request.GetMessage(); msg != nil {
+			o.update(ctx, func(r *TaskRecord) {
+				r.Task.History = append(r.Task.History, msg)
+			})
+		}
+	case *mesh.StreamEvent_StatusUpdate:
+		o.setTaskID(e.StatusUpdate.TaskId)
+		var violation error
+		o.update(ctx, func(r *TaskRecord) {
+			if err := lifecycle.ValidateTransition(r.Task.Status, e.StatusUpdate.Status); err != nil {
+				violation = status.Errorf(codes.FailedPrecondition, "task %s: %v", r.Task.Id, err)
+				return
+			}
+			applyStatus(r.Task, e.StatusUpdate)
+			if n := len(r.Transitions); n == 0 || r.Transitions[n-1].Status != r.Task.Status {
+				r.Transitions = append(r.Transitions, StatusTransition{Status: r.Task.Status, At: time.Now()})
+			}
+		})
+		return violation
+	case *mesh.StreamEvent_ArtifactUpdate:
+		o.setTaskID(e.ArtifactUpdate.TaskId)
+		o.update(ctx, func(r *TaskRecord) {
+			r.Task.Artifacts = append(r.Task.Artifacts, e.ArtifactUpdate.Artifact)
+		})
+	}
+	return nil
+}
+
+// update applies fn to the observed task, initialising the record from the
+// TaskStart request the first time the task is seen. Callers hold o.mu.
+func (o *taskObserver) update(ctx context.Context, fn func(*TaskRecord)) {
+	if o.key.ID == "" {
+		return
+	}
+
+	_, err := o.store.Update(ctx, o.key, func(r *TaskRecord) {
+		// Transitions are checked per stream: a stream starting a new task may reuse
+		// the ID of a finished one, which then starts over.
+		if o.request != nil && !o.recorded && lifecycle.IsTerminal(r.Task.Status) {
+			*r = TaskRecord{Task: &mesh.Task{Id: o.key.ID}, Direction: r.Direction, Peer: r.Peer, CreatedAt: time.Now()}
+		}
+		o.recorded = true
+		if r.Task.Status == mesh.Task_STATUS_UNSPECIFIED {
+			r.Task.Status = mesh.Task_SUBMITTED
+			r.Transitions = []StatusTransition{{Status: mesh.Task_SUBMITTED, At: r.CreatedAt}}
+			if o.request != nil {
+				r.Task.SessionId = o.request.ContextId
+				if o.request.Message != nil {
+					r.Task.History = append(r.Task.History, o.request.Message)
+				}
+			}
+		}
+		fn(r)
+	})
+	if err != nil {
+		log.Printf("Failed to record task %s: %v", o.key.ID, err)
+	}
+}
+
+// forwardMetadata copies the incoming metadata to the outgoing context. On calls from a
+// Remote Sidecar, x-caller-id is set to the identity in its certificate, replacing
+// anything the caller sent, so the Local Agent can tell its callers apart.
+func forwardMetadata(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	md = md.Copy()
+	if isInbound(ctx) {
+		md.Delete("x-caller-id")
+		if id := peerIdentity(ctx); id != "" {
+			md.Set("x-caller-id", id)
+		}
+	}
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// runTask starts req on client as a stream and drives it until the task reaches a
+// terminal or interrupted status or the stream ends, assembling the resulting Task.
+// Every event is recorded in the task store, and a lifecycle violation aborts the task.
+func (s *Server) runTask(ctx context.Context, client mesh.A2AMeshServiceClient, req *mesh.TaskSendRequest, direction Direction, peer string) (_ *mesh.Task, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	obs := s.observer(direction, peer, cancel)
+	defer func() { obs.finish(ctx, err) }()
+
+	stream, err := client.StreamTask(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start stream: %w", err)
+	}
+
+	start := &mesh.StreamEvent{
+		Event: &mesh.StreamEvent_TaskStart{
+			TaskStart: &mesh.TaskStart{Request: req},
+		},
+	}
+	if err := stream.Send(start); err != nil {
+		return nil, fmt.Errorf("failed to send task start: %w", err)
+	}
+	obs.observe(ctx, start)
+	stream.CloseSend()
+
+	task := &mesh.Task{
+		Id:        req.TaskId,
+		SessionId: req.ContextId,
+		Status:    mesh.Task_SUBMITTED,
+	}
+	if req.Message != nil {
+		task.History = append(task.History, req.Message)
+	}
+
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return task, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := obs.observe(ctx, event); err != nil {
+			return nil, err
+		}
+
+		switch e := event.Event.(type) {
+		case *mesh.StreamEvent_StatusUpdate:
+			applyStatus(task, e.StatusUpdate)
+			if lifecycle.IsTerminal(task.Status) || lifecycle.IsInterrupted(task.Status) {
+				return task, nil
+			}
+		case *mesh.StreamEvent_ArtifactUpdate:
+			if task.Id == "" {
+				task.Id = e.ArtifactUpdate.TaskId
+			}
+			task.Artifacts = append(task.Artifacts, e.ArtifactUpdate.Artifact)
+		}
+	}
+}
+
+// applyStatus folds a status update into task, appending the agent's message and
+// structured data to the history and keeping the error of a failed task.
+func applyStatus(task *mesh.Task, update *mesh.TaskStatusUpdate) {
+	if task.Id == "" {
+		task.Id = update.TaskId
+	}
+	task.Status = update.Status
+	if update.Error != nil {
+		task.Error = update.Error
+	}
+
+	var parts []*mesh.Part
+	if update.Message != "" {
+		parts = append(parts, &mesh.Part{Content: &mesh.Part_TextPart{TextPart: update.Message}})
+	}
+	if update.Data != nil {
+		parts = append(parts, &mesh.Part{Content: &mesh.Part_DataPart{DataPart: update.Data}})
+	}
+	if len(parts) > 0 {
+		task.History = append(task.History, &mesh.Message{
+			Role:      "agent",
+			CreatedAt: timestamppb.Now(),
+			Parts:     parts,
+		})
+	}
+}
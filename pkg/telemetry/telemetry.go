@@ -0,0 +1,127 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+// InstrumentationName is the name used for all tracers created by AgentMesh.
+const InstrumentationName = "github.com/ThisaraWeerakoon/Agent-Mesh"
+
+// Supported exporter names for Config.Exporter.
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Config holds the tracing configuration for a single process.
+type Config struct {
+	// ServiceName is reported as the service.name resource attribute.
+	ServiceName string
+	// Exporter selects the span exporter: "none", "stdout" or "otlp".
+	Exporter string
+	// SpanExporter, if set, overrides Exporter. Tests use this to inject a
+	// tracetest.InMemoryExporter and assert on the recorded spans.
+	SpanExporter sdktrace.SpanExporter
+	// Sync exports each span as soon as it ends instead of in batches, so that
+	// tests can assert on it right away.
+	Sync bool
+}
+
+// ConfigFromEnv builds a Config from the AGENTMESH_TRACE_EXPORTER environment variable.
+// The OTLP exporter additionally honours the standard OTEL_EXPORTER_OTLP_* variables.
+func ConfigFromEnv(serviceName string) Config {
+	exporter := os.Getenv("AGENTMESH_TRACE_EXPORTER")
+	if exporter == "" {
+		exporter = ExporterNone
+	}
+	return Config{
+		ServiceName: serviceName,
+		Exporter:    exporter,
+	}
+}
+
+// Setup installs a global TracerProvider and the W3C trace-context propagator.
+// The returned function flushes and shuts down the provider.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	// The propagator is always installed so that traceparent is forwarded
+	// across hops even when this process does not export spans itself.
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	exporter := cfg.SpanExporter
+	if exporter == nil {
+		var err error
+		exporter, err = newExporter(ctx, cfg.Exporter)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if exporter == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+	if cfg.Sync {
+		opts = append(opts, sdktrace.WithSyncer(exporter))
+	} else {
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	tp := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
+
+func newExporter(ctx context.Context, name string) (sdktrace.SpanExporter, error) {
+	switch strings.ToLower(name) {
+	case "", ExporterNone:
+		return nil, nil
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterOTLP:
+		return otlptracegrpc.New(ctx)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter: %q", name)
+	}
+}
+
+// Tracer returns the AgentMesh tracer from the global TracerProvider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+// ServerOption instruments a gRPC server and extracts the incoming traceparent.
+func ServerOption() grpc.ServerOption {
+	return grpc.StatsHandler(otelgrpc.NewServerHandler())
+}
+
+// DialOption instruments a gRPC client and injects traceparent into outgoing metadata.
+func DialOption() grpc.DialOption {
+	return grpc.WithStatsHandler(otelgrpc.NewClientHandler())
+}
@@ -0,0 +1,157 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	adkagent "google.golang.org/adk/agent"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/adk"
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+)
+
+// ask runs one task on client and returns the agent's response.
+func ask(t *testing.T, ctx context.Context, client mesh.A2AMeshServiceClient, req *mesh.TaskSendRequest) []string {
+	t.Helper()
+	return response(recvAll(t, ctx, client, req))
+}
+
+// recvAll runs one task on client and returns all events of its stream.
+func recvAll(t *testing.T, ctx context.Context, client mesh.A2AMeshServiceClient, req *mesh.TaskSendRequest) []*mesh.StreamEvent {
+	t.Helper()
+	stream, err := client.StreamTask(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&mesh.StreamEvent{Event: &mesh.StreamEvent_TaskStart{TaskStart: &mesh.TaskStart{Request: req}}}))
+	require.NoError(t, stream.CloseSend())
+
+	var events []*mesh.StreamEvent
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return events
+		}
+		require.NoError(t, err)
+		events = append(events, event)
+	}
+}
+
+func TestAgentServer(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	var streams atomic.Int32
+	server, err := adk.NewAgentServer(adk.AgentServerConfig{
+		Agents:   []adkagent.Agent{historyAgent(t), mirrorAgent(t)},
+		AgentID:  "did:peer:hosted",
+		Listener: lis,
+		StreamInterceptors: []grpc.StreamServerInterceptor{
+			func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+				streams.Add(1)
+				return handler(srv, ss)
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	cards := server.AgentCards()
+	require.Len(t, cards, 2)
+	assert.Equal(t, "history_agent", cards[0].Name)
+	assert.Equal(t, "mirror_agent", cards[1].Skills[0].Id)
+	assert.Equal(t, "Returns its input.", cards[1].Description)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(ctx) }()
+
+	conn, err := grpc.NewClient(server.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := mesh.NewA2AMeshServiceClient(conn)
+
+	bg := context.Background()
+	assert.Equal(t, []string{"hello"}, ask(t, bg, client, &mesh.TaskSendRequest{TargetAgentId: "mirror_agent", Message: userMessage("hello")}))
+	skillCtx := metadata.AppendToOutgoingContext(bg, "x-target-skill", "mirror_agent")
+	assert.Equal(t, []string{"hello"}, ask(t, skillCtx, client, &mesh.TaskSendRequest{Message: userMessage("hello")}))
+	assert.Equal(t, []string{"events: 1"}, ask(t, bg, client, &mesh.TaskSendRequest{Message: userMessage("hello")}), "first agent by default")
+	assert.Equal(t, []string{"hello"}, ask(t, skillCtx, client, &mesh.TaskSendRequest{TargetAgentId: "did:peer:hosted", Message: userMessage("hello")}), "server DID with skill")
+	assert.Equal(t, int32(4), streams.Load())
+
+	// Tasks for targets the server does not host are rejected.
+	for _, req := range []*mesh.TaskSendRequest{
+		{TargetAgentId: "weather_agent", Message: userMessage("hello")},
+		{TargetAgentId: "did:peer:other", Message: userMessage("hello")},
+	} {
+		events := recvAll(t, bg, client, req)
+		require.Len(t, events, 1, req.TargetAgentId)
+		update := events[0].GetStatusUpdate()
+		assert.Equal(t, mesh.Task_REJECTED, update.Status, req.TargetAgentId)
+		assert.Equal(t, "NotFound", update.Error.Code)
+	}
+	unknownSkill := metadata.AppendToOutgoingContext(bg, "x-target-skill", "forecast")
+	events := recvAll(t, unknownSkill, client, &mesh.TaskSendRequest{Message: userMessage("hello")})
+	require.Len(t, events, 1)
+	assert.Equal(t, "no hosted agent for forecast", events[0].GetStatusUpdate().Message)
+
+	// Cancelling the context stops the server gracefully.
+	cancel()
+	select {
+	case err := <-served:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("server did not stop")
+	}
+}
+
+func TestAgentServerShutdownTimeout(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	agent, runStopped := blockingAgent(t)
+	server, err := adk.NewAgentServer(adk.AgentServerConfig{
+		Agents:          []adkagent.Agent{agent},
+		Listener:        lis,
+		ShutdownTimeout: 100 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(ctx) }()
+
+	conn, err := grpc.NewClient(server.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	stream, err := mesh.NewA2AMeshServiceClient(conn).StreamTask(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&mesh.StreamEvent{Event: &mesh.StreamEvent_TaskStart{TaskStart: &mesh.TaskStart{
+		Request: &mesh.TaskSendRequest{Message: userMessage("wait")},
+	}}}))
+	_, err = stream.Recv()
+	require.NoError(t, err, "task started")
+
+	// The task never ends on its own: once the timeout has passed, it is canceled.
+	cancel()
+	select {
+	case err := <-served:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("server did not stop")
+	}
+	select {
+	case <-runStopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("run was not canceled")
+	}
+	for err == nil {
+		_, err = stream.Recv()
+	}
+	assert.NotEqual(t, io.EOF, err, "the stream is cut off")
+}
@@ -0,0 +1,219 @@
+package tests
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/ThisaraWeerakoon/Agent-Mesh/internal/adapters/auth"
+	grpcHandler "github.com/ThisaraWeerakoon/Agent-Mesh/internal/adapters/handler/grpc"
+	httpHandler "github.com/ThisaraWeerakoon/Agent-Mesh/internal/adapters/handler/http"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/internal/adapters/repository/memory"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/internal/config"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/internal/core/domain"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/internal/core/services"
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+	pb "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/registry"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/meshtest"
+)
+
+func TestPrincipalCanOnlyModifyOwnAgent(t *testing.T) {
+	service := services.NewRegistryService(memory.NewRegistryRepository())
+
+	owner := domain.WithPrincipal(context.Background(), domain.Principal{Name: "sidecar-a"})
+	other := domain.WithPrincipal(context.Background(), domain.Principal{Name: "sidecar-b"})
+	admin := domain.WithPrincipal(context.Background(), domain.Principal{Name: "ops", Admin: true})
+
+	card := domain.AgentCard{DID: "did:peer:owned", Name: "owned", ProtocolVersion: "1.0"}
+	_, err := service.RegisterAgent(owner, card, nil, nil, "sidecar-a")
+	require.NoError(t, err)
+
+	_, err = service.Heartbeat(owner, "did:peer:owned")
+	assert.NoError(t, err)
+
+	_, err = service.Heartbeat(other, "did:peer:owned")
+	assert.EqualError(t, err, "permission denied")
+
+	_, err = service.UpdateAgent(other, "did:peer:owned", card, nil, nil)
+	assert.EqualError(t, err, "permission denied")
+
+	assert.EqualError(t, service.DeleteAgent(other, "did:peer:owned"), "permission denied")
+	assert.NoError(t, service.DeleteAgent(admin, "did:peer:owned"))
+}
+
+func TestPrincipalCannotRegisterOthersAgent(t *testing.T) {
+	service := services.NewRegistryService(memory.NewRegistryRepository())
+
+	owner := domain.WithPrincipal(context.Background(), domain.Principal{Name: "sidecar-a"})
+	other := domain.WithPrincipal(context.Background(), domain.Principal{Name: "sidecar-b"})
+
+	card := domain.AgentCard{DID: "did:peer:owned", Name: "owned", ProtocolVersion: "1.0"}
+	_, err := service.RegisterAgent(owner, card, nil, nil, "sidecar-a")
+	require.NoError(t, err)
+	require.NoError(t, service.DeleteAgent(owner, "did:peer:owned"))
+
+	// The DID stays bound to sidecar-a once its agent is gone.
+	_, err = service.RegisterAgent(other, card, nil, nil, "sidecar-b")
+	assert.EqualError(t, err, "permission denied")
+	_, err = service.GetAgent(other, "did:peer:owned")
+	assert.EqualError(t, err, "agent not found")
+
+	_, err = service.RegisterAgent(owner, card, nil, nil, "sidecar-a")
+	assert.NoError(t, err)
+}
+
+func TestRegistryOwnershipThroughMesh(t *testing.T) {
+	m := meshtest.New(t, meshtest.Config{Auth: true})
+	card := `{"name": "owned", "protocolVersion": "1.0"}`
+	owned := m.AddAgent("did:peer:owned", card, meshtest.NewAgent(func(ctx context.Context, req *mesh.TaskSendRequest) (string, error) {
+		return "ok", nil
+	}))
+
+	ctx := context.Background()
+	entry, err := m.Registry().GetAgent(ctx, &pb.GetAgentRequest{AgentId: "did:peer:owned"})
+	require.NoError(t, err)
+	assert.Equal(t, "did:peer:owned", entry.Owner, "the sidecar's certificate names the owner")
+
+	// Another sidecar can neither heartbeat nor take over the agent...
+	other := m.RegistryAs("did:peer:other")
+	_, err = other.Heartbeat(ctx, &pb.HeartbeatRequest{AgentId: "did:peer:owned"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = other.UpdateAgent(ctx, &pb.UpdateAgentRequest{AgentId: "did:peer:owned", AgentCard: entry.AgentCard})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// ...nor register its DID once the owner has deregistered it.
+	require.NoError(t, owned.Stop())
+	_, err = m.Registry().GetAgent(ctx, &pb.GetAgentRequest{AgentId: "did:peer:owned"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	_, err = other.RegisterAgent(ctx, &pb.RegisterAgentRequest{AgentCard: entry.AgentCard})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = m.RegistryAs("did:peer:owned").RegisterAgent(ctx, &pb.RegisterAgentRequest{AgentCard: entry.AgentCard})
+	assert.NoError(t, err)
+}
+
+// tlsPeer returns ctx as seen by a handler whose caller presented a verified
+// certificate for commonName, or no certificate when commonName is empty.
+func tlsPeer(ctx context.Context, commonName string) context.Context {
+	var state tls.ConnectionState
+	if commonName != "" {
+		leaf := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+		state.VerifiedChains = [][]*x509.Certificate{{leaf}}
+	}
+	return peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+}
+
+func TestAuthenticatorPrincipals(t *testing.T) {
+	a := auth.NewAuthenticator(config.AuthConfig{
+		Mode:       "mtls",
+		Principals: map[string]string{"sidecar-a.mesh.local": "sidecar-a"},
+		Admins:     []string{"ops"},
+	})
+	chain := func(cn string) [][]*x509.Certificate {
+		return [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: cn}}}}
+	}
+
+	p, err := a.Authenticate(chain("sidecar-a.mesh.local"))
+	require.NoError(t, err)
+	assert.Equal(t, domain.Principal{Name: "sidecar-a"}, p)
+
+	p, err = a.Authenticate(chain("sidecar-b"))
+	require.NoError(t, err)
+	assert.Equal(t, domain.Principal{Name: "sidecar-b"}, p)
+
+	p, err = a.Authenticate(chain("ops"))
+	require.NoError(t, err)
+	assert.Equal(t, domain.Principal{Name: "ops", Admin: true}, p)
+
+	_, err = a.Authenticate(chain(""))
+	assert.EqualError(t, err, "client certificate has no common name")
+	_, err = a.Authenticate(nil)
+	assert.EqualError(t, err, "client certificate required")
+}
+
+func TestAuthInterceptors(t *testing.T) {
+	a := auth.NewAuthenticator(config.AuthConfig{Mode: "mtls", Principals: map[string]string{"sidecar-a.mesh.local": "sidecar-a"}})
+	unary := grpcHandler.UnaryAuthInterceptor(a)
+	stream := grpcHandler.StreamAuthInterceptor(a)
+	registryMethod := "/" + pb.RegistryService_ServiceDesc.ServiceName + "/UpdateAgent"
+	healthMethod := "/" + healthpb.Health_ServiceDesc.ServiceName + "/Watch"
+
+	// principal runs both interceptors for method on ctx and returns the principal
+	// the handlers saw, or the interceptors' error.
+	principal := func(ctx context.Context, method string) (string, error) {
+		var fromUnary, fromStream string
+		_, err := unary(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req any) (any, error) {
+			p, _ := domain.PrincipalFromContext(ctx)
+			fromUnary = p.Name
+			return nil, nil
+		})
+		streamErr := stream(nil, &contextStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: method}, func(srv any, ss grpc.ServerStream) error {
+			p, _ := domain.PrincipalFromContext(ss.Context())
+			fromStream = p.Name
+			return nil
+		})
+		assert.Equal(t, status.Code(err), status.Code(streamErr), method)
+		assert.Equal(t, fromUnary, fromStream, method)
+		return fromUnary, err
+	}
+
+	name, err := principal(tlsPeer(context.Background(), "sidecar-a.mesh.local"), registryMethod)
+	require.NoError(t, err)
+	assert.Equal(t, "sidecar-a", name)
+
+	_, err = principal(tlsPeer(context.Background(), ""), registryMethod)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = principal(context.Background(), registryMethod)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// Probes reach grpc.health.v1 without a certificate.
+	_, err = principal(tlsPeer(context.Background(), ""), healthMethod)
+	assert.NoError(t, err)
+
+	// Without mtls, nobody is identified.
+	none := auth.NewAuthenticator(config.AuthConfig{Mode: "none"})
+	unary, stream = grpcHandler.UnaryAuthInterceptor(none), grpcHandler.StreamAuthInterceptor(none)
+	name, err = principal(context.Background(), registryMethod)
+	require.NoError(t, err)
+	assert.Empty(t, name)
+}
+
+func TestAuthMiddlewareExemptsProbes(t *testing.T) {
+	a := auth.NewAuthenticator(config.AuthConfig{Mode: "mtls"})
+	router := httpHandler.SetupRouter(setupRouter(), httpHandler.AuthMiddleware(a))
+
+	for path, want := range map[string]int{
+		"/livez":          http.StatusOK,
+		"/readyz":         http.StatusOK,
+		"/api/v1/agents/": http.StatusUnauthorized,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		req.TLS = &tls.ConnectionState{}
+		router.ServeHTTP(w, req)
+		assert.Equal(t, want, w.Code, path)
+	}
+}
+
+// contextStream is a grpc.ServerStream carrying only a context.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
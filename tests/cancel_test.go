@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"iter"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	adkagent "google.golang.org/adk/agent"
+	"google.golang.org/adk/session"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/adk"
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+)
+
+// blockingAgent builds an ADK agent whose runs only end when canceled; each
+// finished run is reported on the returned channel.
+func blockingAgent(t *testing.T) (adkagent.Agent, <-chan struct{}) {
+	t.Helper()
+	stopped := make(chan struct{}, 4)
+	agent, err := adkagent.New(adkagent.Config{
+		Name:        "blocking_agent",
+		Description: "Never answers.",
+		Run: func(ctx adkagent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				<-ctx.Done()
+				stopped <- struct{}{}
+			}
+		},
+	})
+	require.NoError(t, err)
+	return agent, stopped
+}
+
+// startTask opens a stream through the caller's sidecar and returns it with the
+// task ID announced by the remote agent.
+func startTask(t *testing.T, ctx context.Context, client mesh.A2AMeshServiceClient) (mesh.A2AMeshService_StreamTaskClient, string) {
+	t.Helper()
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-target-skill", "blocking")
+	stream, err := client.StreamTask(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&mesh.StreamEvent{Event: &mesh.StreamEvent_TaskStart{
+		TaskStart: &mesh.TaskStart{Request: &mesh.TaskSendRequest{
+			Message: &mesh.Message{Role: "user", Parts: []*mesh.Part{{Content: &mesh.Part_TextPart{TextPart: "wait"}}}},
+		}},
+	}}))
+	require.NoError(t, stream.CloseSend())
+
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, mesh.Task_WORKING, event.GetStatusUpdate().Status)
+	return stream, event.GetStatusUpdate().TaskId
+}
+
+func TestCancellationPropagation(t *testing.T) {
+	agent, stopped := blockingAgent(t)
+	client := startMesh(t, "did:peer:blocking", "blocking", adk.NewServerWrapper(agent))
+
+	t.Run("caller context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		_, taskID := startTask(t, ctx, client)
+
+		cancel()
+		select {
+		case <-stopped:
+		case <-time.After(2 * time.Second):
+			t.Fatal("remote run was not canceled")
+		}
+
+		require.Eventually(t, func() bool {
+			task, err := client.GetTask(context.Background(), &mesh.GetTaskRequest{Id: taskID})
+			return err == nil && task.Status == mesh.Task_CANCELED
+		}, 2*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("CancelTask", func(t *testing.T) {
+		stream, taskID := startTask(t, context.Background(), client)
+
+		_, err := client.CancelTask(context.Background(), &mesh.CancelTaskRequest{Id: taskID})
+		require.NoError(t, err)
+
+		event, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, mesh.Task_CANCELED, event.GetStatusUpdate().Status)
+		_, err = stream.Recv()
+		assert.Equal(t, io.EOF, err)
+		<-stopped
+
+		task, err := client.GetTask(context.Background(), &mesh.GetTaskRequest{Id: taskID})
+		require.NoError(t, err)
+		assert.Equal(t, mesh.Task_CANCELED, task.Status)
+	})
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	adkagent "google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/tool"
+	"google.golang.org/genai"
+
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/adk"
+	pb "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/registry"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/meshtest"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/sidecar"
+)
+
+func skillIDs(card *pb.AgentCard) []string {
+	var ids []string
+	for _, skill := range card.Skills {
+		ids = append(ids, skill.Id)
+	}
+	return ids
+}
+
+func TestNewAgentCard(t *testing.T) {
+	requestInput, err := adk.RequestInputTool()
+	require.NoError(t, err)
+	agent, err := llmagent.New(llmagent.Config{
+		Name:         "greeter",
+		Description:  "Greets the caller by name.",
+		Model:        greeterModel{},
+		Tools:        []tool.Tool{requestInput},
+		SubAgents:    []adkagent.Agent{mirrorAgent(t)},
+		OutputSchema: &genai.Schema{Type: genai.TypeObject},
+	})
+	require.NoError(t, err)
+
+	// The llmagent's tools and schemas are not visible on the agent.
+	card := adk.NewAgentCard(agent, adk.AgentCardConfig{})
+	assert.Equal(t, []string{"greeter", "mirror_agent"}, skillIDs(card))
+	assert.Equal(t, []string{"text/plain"}, card.DefaultOutputModes)
+
+	card = adk.NewAgentCard(agent, adk.AgentCardConfig{
+		Tools:        []tool.Tool{requestInput},
+		OutputSchema: &genai.Schema{Type: genai.TypeObject},
+	})
+	assert.Equal(t, "greeter", card.Name)
+	assert.Equal(t, "Greets the caller by name.", card.Description)
+	assert.Equal(t, []string{"greeter", "mirror_agent", "request_input"}, skillIDs(card))
+	assert.Equal(t, "Returns its input.", card.Skills[1].Description)
+	assert.Equal(t, []string{"text/plain"}, card.DefaultInputModes)
+	assert.Equal(t, []string{"text/plain", "application/json"}, card.DefaultOutputModes)
+	assert.True(t, card.Capabilities.Streaming)
+
+	card = adk.NewAgentCard(agent, adk.AgentCardConfig{
+		Description: "Says hello.",
+		Version:     "1.2.0",
+		Tools:       []tool.Tool{requestInput},
+		InputModes:  []string{"application/json"},
+		Skills:      []*pb.AgentSkill{{Id: "mirror_agent", Name: "Mirror", Tags: []string{"echo"}}},
+	})
+	assert.Equal(t, "Says hello.", card.Description)
+	assert.Equal(t, "1.2.0", card.Version)
+	assert.Equal(t, []string{"application/json"}, card.DefaultInputModes)
+	assert.Equal(t, []string{"greeter", "mirror_agent", "request_input"}, skillIDs(card))
+	assert.Equal(t, "Mirror", card.Skills[1].Name)
+}
+
+func TestAgentServerCard(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server, err := adk.NewAgentServer(adk.AgentServerConfig{
+		Agents:   []adkagent.Agent{historyAgent(t), mirrorAgent(t)},
+		Listener: lis,
+		Card:     adk.AgentCardConfig{Version: "1.2.0"},
+		CardAddr: "127.0.0.1:0",
+	})
+	require.NoError(t, err)
+	go server.Serve(t.Context())
+
+	card := server.Card()
+	assert.Equal(t, "history_agent", card.Name)
+	assert.Equal(t, "1.2.0", card.Version)
+	assert.Equal(t, []string{"history_agent", "mirror_agent"}, skillIDs(card))
+
+	// The Sidecar registers the served card under its own DID and address.
+	registryAddr, service := startRegistry(t)
+	m := meshtest.New(t, meshtest.Config{RegistryAddr: registryAddr})
+	m.StartSidecar(sidecar.Config{
+		AgentID:       "did:peer:hosted",
+		AppPort:       lis.Addr().(*net.TCPAddr).Port,
+		AgentCardURL:  server.CardURL(),
+		AdvertiseAddr: "sidecar.example:50053",
+	})
+	m.WaitRegistered("did:peer:hosted")
+	entry, err := service.GetAgent(context.Background(), "did:peer:hosted")
+	require.NoError(t, err)
+	assert.Equal(t, "history_agent", entry.AgentCard.Name)
+	require.Len(t, entry.AgentCard.Skills, 2)
+	assert.Equal(t, "mirror_agent", entry.AgentCard.Skills[1].ID)
+	require.Len(t, entry.AgentCard.SupportedInterfaces, 1)
+	assert.Equal(t, "sidecar.example:50053", entry.AgentCard.SupportedInterfaces[0].URL)
+}
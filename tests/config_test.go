@@ -0,0 +1,167 @@
+package tests
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ThisaraWeerakoon/Agent-Mesh/internal/config"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/sidecar"
+)
+
+func TestConfigPrecedence(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "registry.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("http:\n  port: 4000\ngrpc:\n  port: 4001\nshutdownTimeout: 5s\n"), 0o600))
+
+	t.Setenv("GRPC_PORT", "4002")
+
+	cfg, err := config.Load([]string{"--config", file, "--shutdown-timeout", "1s"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 4000, cfg.HTTP.Port)              // file
+	assert.Equal(t, 4002, cfg.GRPC.Port)              // env over file
+	assert.Equal(t, time.Second, cfg.ShutdownTimeout) // flag over file
+	assert.Equal(t, "memory", cfg.Storage.Backend)    // default
+}
+
+func TestConfigValidation(t *testing.T) {
+	_, err := config.Load([]string{"--http-port", "5000", "--grpc-port", "5000"})
+	assert.Error(t, err)
+
+	_, err = config.Load([]string{"--storage", "postgres"})
+	assert.Error(t, err)
+
+	_, err = config.Load([]string{"--tls-cert", "missing.pem", "--tls-key", "missing.pem"})
+	assert.Error(t, err)
+}
+
+// sidecarCerts writes empty certificate files, which is all Validate checks for,
+// and returns the flags pointing the sidecar at them.
+func sidecarCerts(t *testing.T) []string {
+	t.Helper()
+	dir := t.TempDir()
+	var args []string
+	for _, name := range []string{"cert", "key", "ca"} {
+		file := filepath.Join(dir, name+".pem")
+		require.NoError(t, os.WriteFile(file, nil, 0o600))
+		args = append(args, "--"+name, file)
+	}
+	return args
+}
+
+func TestSidecarConfigPrecedence(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "sidecar.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("agentId: did:peer:file\nlocalPort: 6000\nexternalPort: 6001\ntaskTtl: 5m\n"), 0o600))
+
+	tests := []struct {
+		name  string
+		env   map[string]string
+		args  []string
+		check func(t *testing.T, cfg sidecar.Config)
+	}{
+		{
+			name: "defaults",
+			check: func(t *testing.T, cfg sidecar.Config) {
+				assert.Equal(t, "agent-1", cfg.AgentID)
+				assert.Equal(t, 50052, cfg.LocalPort)
+				assert.Equal(t, time.Hour, cfg.TaskTTL)
+			},
+		},
+		{
+			name: "file over defaults",
+			args: []string{"--config", file},
+			check: func(t *testing.T, cfg sidecar.Config) {
+				assert.Equal(t, "did:peer:file", cfg.AgentID)
+				assert.Equal(t, 6000, cfg.LocalPort)
+				assert.Equal(t, 5*time.Minute, cfg.TaskTTL)
+				assert.Equal(t, 50054, cfg.AppPort)
+			},
+		},
+		{
+			name: "env over file",
+			env:  map[string]string{"AGENTMESH_CONFIG": file, "AGENTMESH_AGENT_ID": "did:peer:env", "AGENTMESH_TASK_TTL": "10m"},
+			check: func(t *testing.T, cfg sidecar.Config) {
+				assert.Equal(t, "did:peer:env", cfg.AgentID)
+				assert.Equal(t, 6000, cfg.LocalPort)
+				assert.Equal(t, 10*time.Minute, cfg.TaskTTL)
+			},
+		},
+		{
+			name: "flags over env",
+			env:  map[string]string{"AGENTMESH_AGENT_ID": "did:peer:env", "AGENTMESH_LOCAL_PORT": "7000"},
+			args: []string{"--config", file, "--agent-id", "did:peer:flag", "--task-ttl", "1m"},
+			check: func(t *testing.T, cfg sidecar.Config) {
+				assert.Equal(t, "did:peer:flag", cfg.AgentID)
+				assert.Equal(t, 7000, cfg.LocalPort)
+				assert.Equal(t, time.Minute, cfg.TaskTTL)
+			},
+		},
+		{
+			name: "no local agent",
+			args: []string{"--app-port", "0"},
+			check: func(t *testing.T, cfg sidecar.Config) {
+				assert.Zero(t, cfg.AppPort)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			cfg, err := sidecar.LoadConfig(append(sidecarCerts(t), tt.args...))
+			require.NoError(t, err)
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestSidecarConfigValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+		want string
+	}{
+		{name: "port collision", args: []string{"--local-port", "6000", "--app-port", "6000"}, want: "localPort and appPort both use port 6000"},
+		{name: "port out of range", args: []string{"--external-port", "70000"}, want: "externalPort 70000 out of range"},
+		{name: "missing agent id", args: []string{"--agent-id", ""}, want: "agentId is required"},
+		{name: "missing card file", args: []string{"--agent-card", "missing.json"}, want: "agentCardFile: stat missing.json"},
+		{name: "card file and url", args: []string{"--agent-card-url", "http://localhost/card.json", "--agent-card", "sidecar.yaml"}, want: "mutually exclusive"},
+		{name: "negative ttl", args: []string{"--task-ttl", "-1s"}, want: "taskTtl must not be negative"},
+		{name: "invalid env", env: map[string]string{"AGENTMESH_APP_PORT": "app"}, want: "invalid AGENTMESH_APP_PORT"},
+		{name: "unknown flag", args: []string{"--no-such-flag"}, want: "flag provided but not defined"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			_, err := sidecar.LoadConfig(append(sidecarCerts(t), tt.args...))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestSidecarPrintConfig(t *testing.T) {
+	// An invalid configuration is still merged, so it can be printed.
+	cfg, err := sidecar.LoadConfig([]string{"--print-config", "--agent-id", "did:peer:print", "--cert", "missing.pem"})
+	assert.Error(t, err)
+	assert.True(t, cfg.PrintConfig)
+	assert.Equal(t, "did:peer:print", cfg.AgentID)
+	assert.Equal(t, "missing.pem", cfg.CertFile)
+
+	out, err := yaml.Marshal(cfg)
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "PrintConfig")
+
+	_, err = sidecar.LoadConfig([]string{"--help"})
+	assert.ErrorIs(t, err, flag.ErrHelp)
+}
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	adkagent "google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/genai"
+
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/adk"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/adk/llmtest"
+)
+
+// runSummaryExample runs the ADK example offline: a root agent asks the remote
+// summary agent, served by a ServerWrapper behind two sidecars, through a RemoteTool.
+// The summary agent is served with cfg. It returns the events of the root agent's run.
+func runSummaryExample(t *testing.T, cfg adk.ServerConfig, summaryModel, rootModel *llmtest.Model) []*session.Event {
+	t.Helper()
+	summary, err := llmagent.New(llmagent.Config{
+		Name:        "summary_agent",
+		Description: "Summarizes text.",
+		Model:       summaryModel,
+	})
+	require.NoError(t, err)
+	startMesh(t, "did:peer:summary", "summarization", adk.NewServerWrapperWithConfig(summary, cfg))
+
+	summaryTool, err := adk.RemoteTool("summary_agent", "Summarizes text.", "summarization")
+	require.NoError(t, err)
+	root, err := llmagent.New(llmagent.Config{
+		Name:  "root_agent",
+		Model: rootModel,
+		Tools: []tool.Tool{summaryTool},
+	})
+	require.NoError(t, err)
+
+	sessions := session.InMemoryService()
+	_, err = sessions.Create(context.Background(), &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "s"})
+	require.NoError(t, err)
+	r, err := runner.New(runner.Config{AppName: "app", Agent: root, SessionService: sessions})
+	require.NoError(t, err)
+
+	var events []*session.Event
+	msg := genai.NewContentFromText("Summarize the meeting notes.", genai.RoleUser)
+	for evt, err := range r.Run(context.Background(), "user", "s", msg, adkagent.RunConfig{StreamingMode: adkagent.StreamingModeSSE}) {
+		require.NoError(t, err)
+		events = append(events, evt)
+	}
+	return events
+}
+
+// relaySummary answers with what the summary tool returned.
+func relaySummary(req *model.LLMRequest) llmtest.Response {
+	resp := llmtest.FunctionResponse(req, "summary_agent")
+	if status, _ := resp.Response["status"].(string); status == "failed" {
+		taskErr, _ := resp.Response["error"].(map[string]any)
+		return llmtest.Text("The summary failed: " + taskErr["message"].(string))
+	}
+	result, _ := resp.Response["result"].(string)
+	return llmtest.Stream("Summary: ", result)
+}
+
+func TestEndToEndSummary(t *testing.T) {
+	summaryModel := llmtest.New("summary-model", llmtest.Stream("Budget ", "approved."))
+	rootModel := llmtest.New("root-model",
+		llmtest.Call("summary_agent", map[string]any{"request": "Summarize: the budget was approved."}),
+		llmtest.Reply(relaySummary),
+	)
+
+	events := runSummaryExample(t, adk.ServerConfig{}, summaryModel, rootModel)
+
+	var partials []string
+	var final string
+	for _, evt := range events {
+		if evt.Content == nil || evt.Author != "root_agent" {
+			continue
+		}
+		if evt.Partial {
+			partials = append(partials, evt.Content.Parts[0].Text)
+		} else if evt.IsFinalResponse() {
+			final = evt.Content.Parts[0].Text
+		}
+	}
+	assert.Equal(t, []string{"Summary: ", "Budget approved.\n"}, partials)
+	assert.Equal(t, "Summary: Budget approved.\n", final)
+
+	// The summary agent got the request the root agent's model wrote.
+	require.Len(t, summaryModel.Requests(), 1)
+	contents := summaryModel.Requests()[0].Contents
+	assert.Equal(t, "Summarize: the budget was approved.", contents[len(contents)-1].Parts[0].Text)
+	assert.Zero(t, rootModel.Remaining())
+}
+
+func TestEndToEndModelFailure(t *testing.T) {
+	summaryModel := llmtest.New("summary-model", llmtest.Fail(errors.New("model overloaded")))
+	rootModel := llmtest.New("root-model",
+		llmtest.Call("summary_agent", map[string]any{"request": "Summarize: nothing."}),
+		llmtest.Reply(relaySummary),
+	)
+
+	events := runSummaryExample(t, adk.ServerConfig{}, summaryModel, rootModel)
+
+	final := events[len(events)-1]
+	assert.True(t, final.IsFinalResponse())
+	assert.Contains(t, final.Content.Parts[0].Text, "The summary failed: ")
+	assert.Contains(t, final.Content.Parts[0].Text, "model overloaded")
+}
+
+func TestEndToEndStreamingSummary(t *testing.T) {
+	var result string
+	summaryModel := llmtest.New("summary-model", llmtest.Stream("Budget ", "approved."))
+	rootModel := llmtest.New("root-model",
+		llmtest.Call("summary_agent", map[string]any{"request": "Summarize: the budget was approved."}),
+		llmtest.Reply(func(req *model.LLMRequest) llmtest.Response {
+			result, _ = llmtest.FunctionResponse(req, "summary_agent").Response["result"].(string)
+			return llmtest.Text("Done.")
+		}),
+	)
+
+	// The summary model streams its chunks across the mesh; the tool result holds
+	// the answer once, not the chunks as well.
+	runSummaryExample(t, adk.ServerConfig{StreamingMode: adkagent.StreamingModeSSE}, summaryModel, rootModel)
+	assert.Equal(t, "Budget approved.\n", result)
+	assert.Zero(t, rootModel.Remaining())
+}
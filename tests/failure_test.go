@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	adkagent "google.golang.org/adk/agent"
+	"google.golang.org/adk/session"
+
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/adk"
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+)
+
+// brokenSessions is a session service that cannot store sessions.
+type brokenSessions struct {
+	session.Service
+}
+
+func (brokenSessions) Get(context.Context, *session.GetRequest) (*session.GetResponse, error) {
+	return nil, errors.New("session not found")
+}
+
+func (brokenSessions) Create(context.Context, *session.CreateRequest) (*session.CreateResponse, error) {
+	return nil, errors.New("database is down")
+}
+
+func TestServerWrapperStartFailures(t *testing.T) {
+	// An agent whose tree repeats its own name cannot be run.
+	sub, err := adkagent.New(adkagent.Config{Name: "echo_agent"})
+	require.NoError(t, err)
+	badTree, err := adkagent.New(adkagent.Config{Name: "echo_agent", SubAgents: []adkagent.Agent{sub}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		wrapper *adk.ServerWrapper
+		req     *mesh.TaskSendRequest
+		code    string
+		message string
+	}{
+		{
+			name:    "runner",
+			wrapper: adk.NewServerWrapper(badTree),
+			req:     &mesh.TaskSendRequest{Message: userMessage("hello")},
+			code:    "Internal",
+			message: "failed to create runner",
+		},
+		{
+			name:    "session",
+			wrapper: adk.NewServerWrapperWithConfig(historyAgent(t), adk.ServerConfig{SessionService: brokenSessions{}}),
+			req:     &mesh.TaskSendRequest{Message: userMessage("hello")},
+			code:    "Internal",
+			message: "database is down",
+		},
+		{
+			name:    "invalid message",
+			wrapper: adk.NewServerWrapper(historyAgent(t)),
+			req: &mesh.TaskSendRequest{Message: &mesh.Message{Role: "user", Parts: []*mesh.Part{
+				{Content: &mesh.Part_FilePart{FilePart: &mesh.FilePart{MimeType: "image/png"}}},
+			}}},
+			code:    "InvalidArgument",
+			message: "file part has neither inline bytes nor a URI",
+		},
+		{
+			name:    "unknown task",
+			wrapper: adk.NewServerWrapper(historyAgent(t)),
+			req:     &mesh.TaskSendRequest{TaskId: "task-unknown", Message: userMessage("hello")},
+			code:    "NotFound",
+			message: "unknown task: task-unknown",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := startMesh(t, "did:peer:failing", "failing", tt.wrapper)
+			tt.req.TargetAgentId = "did:peer:failing"
+
+			task, err := client.SendTask(context.Background(), tt.req)
+			require.NoError(t, err, "the failure is reported on the task")
+			assert.Equal(t, mesh.Task_FAILED, task.Status)
+			require.NotNil(t, task.Error)
+			assert.Equal(t, tt.code, task.Error.Code)
+			assert.Contains(t, task.Error.Message, tt.message)
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"iter"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+	"google.golang.org/genai"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/adk"
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/lifecycle"
+)
+
+// greeterModel asks for the caller's name with request_input, then greets them.
+type greeterModel struct{}
+
+func (greeterModel) Name() string { return "greeter-model" }
+
+func (greeterModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		last := req.Contents[len(req.Contents)-1]
+		for _, part := range last.Parts {
+			if part.FunctionResponse != nil {
+				name, _ := part.FunctionResponse.Response["answer"].(string)
+				yield(&model.LLMResponse{Content: genai.NewContentFromText("Hello, "+name, genai.RoleModel)}, nil)
+				return
+			}
+		}
+		yield(&model.LLMResponse{Content: &genai.Content{
+			Role: genai.RoleModel,
+			Parts: []*genai.Part{genai.NewPartFromFunctionCall("request_input", map[string]any{
+				"question": "What is your name?",
+			})},
+		}}, nil)
+	}
+}
+
+func greeterAgent(t *testing.T) *adk.ServerWrapper {
+	t.Helper()
+	requestInput, err := adk.RequestInputTool()
+	require.NoError(t, err)
+	agent, err := llmagent.New(llmagent.Config{
+		Name:        "greeter",
+		Description: "Greets the caller by name.",
+		Model:       greeterModel{},
+		Tools:       []tool.Tool{requestInput},
+	})
+	require.NoError(t, err)
+	return adk.NewServerWrapperWithConfig(agent, adk.ServerConfig{ForwardToolEvents: true})
+}
+
+func userMessage(text string) *mesh.Message {
+	return &mesh.Message{Role: "user", Parts: []*mesh.Part{{Content: &mesh.Part_TextPart{TextPart: text}}}}
+}
+
+// recvUntil reads events until a status update is interrupted or terminal and returns
+// that update with all events seen on the way.
+func recvUntil(t *testing.T, stream mesh.A2AMeshService_StreamTaskClient) (*mesh.TaskStatusUpdate, []*mesh.StreamEvent) {
+	t.Helper()
+	var events []*mesh.StreamEvent
+	for {
+		event, err := stream.Recv()
+		require.NoError(t, err)
+		events = append(events, event)
+		update := event.GetStatusUpdate()
+		if update != nil && (lifecycle.IsInterrupted(update.Status) || lifecycle.IsTerminal(update.Status)) {
+			return update, events
+		}
+	}
+}
+
+// response returns the text of the "response" artifacts among events.
+func response(events []*mesh.StreamEvent) []string {
+	var texts []string
+	for _, event := range events {
+		if art := event.GetArtifactUpdate().GetArtifact(); art.GetName() == "response" {
+			texts = append(texts, string(art.GetBytes()))
+		}
+	}
+	return texts
+}
+
+func TestInputRequired(t *testing.T) {
+	client := startMesh(t, "did:peer:greeter", "greeting", greeterAgent(t))
+
+	t.Run("same stream", func(t *testing.T) {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "x-target-skill", "greeting")
+		stream, err := client.StreamTask(ctx)
+		require.NoError(t, err)
+
+		require.NoError(t, stream.Send(&mesh.StreamEvent{Event: &mesh.StreamEvent_TaskStart{
+			TaskStart: &mesh.TaskStart{Request: &mesh.TaskSendRequest{Message: userMessage("Greet me")}},
+		}}))
+		update, events := recvUntil(t, stream)
+		require.Equal(t, mesh.Task_INPUT_REQUIRED, update.Status)
+		assert.Equal(t, "What is your name?", update.Message)
+		taskID := update.TaskId
+
+		// The tool call is reported as structured data before the task pauses.
+		call := events[len(events)-2].GetStatusUpdate().GetData().AsMap()
+		assert.Equal(t, "tool_call", call["type"])
+		assert.Equal(t, "request_input", call["name"])
+
+		require.NoError(t, stream.Send(&mesh.StreamEvent{Event: &mesh.StreamEvent_TaskStart{
+			TaskStart: &mesh.TaskStart{Request: &mesh.TaskSendRequest{Message: userMessage("Ada")}},
+		}}))
+		update, events = recvUntil(t, stream)
+		require.Equal(t, mesh.Task_COMPLETED, update.Status)
+		assert.Equal(t, taskID, update.TaskId)
+		assert.Equal(t, []string{"Hello, Ada"}, response(events))
+
+		require.NoError(t, stream.CloseSend())
+		_, err = stream.Recv()
+		require.Equal(t, io.EOF, err)
+
+		task, err := client.GetTask(context.Background(), &mesh.GetTaskRequest{Id: taskID})
+		require.NoError(t, err)
+		assert.Equal(t, mesh.Task_COMPLETED, task.Status)
+		assert.True(t, slices.ContainsFunc(task.History, func(m *mesh.Message) bool {
+			return proto.Equal(m, userMessage("Ada"))
+		}), "answer recorded in history")
+	})
+
+	t.Run("SendTask with task ID", func(t *testing.T) {
+		task, err := client.SendTask(context.Background(), &mesh.TaskSendRequest{
+			TargetAgentId: "did:peer:greeter",
+			Message:       userMessage("Greet me"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, mesh.Task_INPUT_REQUIRED, task.Status)
+
+		task, err = client.SendTask(context.Background(), &mesh.TaskSendRequest{
+			TaskId:  task.Id,
+			Message: userMessage("Grace"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, mesh.Task_COMPLETED, task.Status)
+		require.Len(t, task.Artifacts, 1)
+		assert.Equal(t, "Hello, Grace", string(task.Artifacts[0].GetBytes()))
+
+		stored, err := client.GetTask(context.Background(), &mesh.GetTaskRequest{Id: task.Id})
+		require.NoError(t, err)
+		assert.Equal(t, mesh.Task_COMPLETED, stored.Status)
+	})
+}
+
+func TestInputRequiredExpires(t *testing.T) {
+	requestInput, err := adk.RequestInputTool()
+	require.NoError(t, err)
+	agent, err := llmagent.New(llmagent.Config{
+		Name:  "greeter",
+		Model: greeterModel{},
+		Tools: []tool.Tool{requestInput},
+	})
+	require.NoError(t, err)
+	wrapper := adk.NewServerWrapperWithConfig(agent, adk.ServerConfig{
+		SessionTTL: 20 * time.Millisecond,
+	})
+	go wrapper.Run(t.Context())
+	client := startMesh(t, "did:peer:greeter", "greeting", wrapper)
+
+	task, err := client.SendTask(context.Background(), &mesh.TaskSendRequest{
+		TargetAgentId: "did:peer:greeter",
+		Message:       userMessage("Greet me"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, mesh.Task_INPUT_REQUIRED, task.Status)
+
+	time.Sleep(100 * time.Millisecond)
+	task, err = client.SendTask(context.Background(), &mesh.TaskSendRequest{
+		TaskId:  task.Id,
+		Message: userMessage("Ada"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, mesh.Task_FAILED, task.Status)
+	assert.Equal(t, "NotFound", task.GetError().GetCode())
+}
@@ -2,12 +2,15 @@ package tests
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	httpHandler "github.com/ThisaraWeerakoon/Agent-Mesh/internal/adapters/handler/http"
 	"github.com/ThisaraWeerakoon/Agent-Mesh/internal/adapters/repository/memory"
@@ -168,3 +171,39 @@ func TestListAgents(t *testing.T) {
 	json.Unmarshal(w.Body.Bytes(), &response)
 	assert.Equal(t, float64(2), response["total"])
 }
+
+func TestProbes(t *testing.T) {
+	handler := setupRouter()
+	router := httpHandler.SetupRouter(handler)
+
+	for _, path := range []string{"/livez", "/readyz"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, 200, w.Code, path)
+	}
+}
+
+func TestExpireAgents(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewRegistryRepository()
+	service := services.NewRegistryService(repo)
+	for _, did := range []string{"did:peer:silent", "did:peer:alive"} {
+		_, err := service.RegisterAgent(ctx, domain.AgentCard{DID: did, Name: did, ProtocolVersion: "1.0"}, nil, nil, "")
+		require.NoError(t, err)
+	}
+
+	cutoff := time.Now().Add(time.Minute)
+	require.NoError(t, repo.UpdateHeartbeat(ctx, "did:peer:alive", cutoff.Add(time.Second)))
+	expired, err := service.ExpireAgents(ctx, cutoff)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"did:peer:silent"}, expired)
+
+	// An agent heartbeating after the expiry listed it is not deleted.
+	deleted, err := repo.DeleteStale(ctx, "did:peer:alive", cutoff)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+	_, err = service.GetAgent(ctx, "did:peer:alive")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,34 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/meshtest"
+)
+
+func TestMeshHarness(t *testing.T) {
+	m := meshtest.New(t, meshtest.Config{})
+	m.AddAgent("did:peer:upper", `{"name": "upper", "protocolVersion": "1.0"}`,
+		meshtest.NewAgent(func(ctx context.Context, req *mesh.TaskSendRequest) (string, error) {
+			return strings.ToUpper(req.Message.Parts[0].GetTextPart()), nil
+		}))
+	m.AddAgent("did:peer:broken", `{"name": "broken", "protocolVersion": "1.0"}`,
+		meshtest.NewAgent(func(ctx context.Context, req *mesh.TaskSendRequest) (string, error) {
+			return "", errors.New("out of order")
+		}))
+	caller := m.AddCaller("did:peer:caller")
+
+	events := caller.Ask("did:peer:upper", "hello")
+	events.AssertStatuses(t, mesh.Task_WORKING, mesh.Task_COMPLETED)
+	events.AssertResponse(t, "HELLO")
+
+	events = caller.Ask("did:peer:broken", "hello")
+	events.AssertStatuses(t, mesh.Task_WORKING, mesh.Task_FAILED)
+	assert.Equal(t, "out of order", events.Final().Message)
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+	pb "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/registry"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/meshtest"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/mockagent"
+)
+
+func TestMockAgentScenario(t *testing.T) {
+	sc := &mockagent.Scenario{Skills: map[string]mockagent.Script{
+		"summarize": {Steps: []mockagent.Step{
+			{Status: "WORKING", Message: "reading"},
+			{Delay: 10 * time.Millisecond, Artifact: &mockagent.Artifact{Name: "response", Text: "summary of {input}"}},
+		}},
+		"translate": {Steps: []mockagent.Step{
+			{Status: "WORKING"},
+			{Input: "Which language?"},
+			{Status: "WORKING"},
+			{Artifact: &mockagent.Artifact{Name: "response", Text: "in {input}"}},
+		}},
+		"overloaded": {Steps: []mockagent.Step{
+			{Status: "WORKING"},
+			{Fail: &mockagent.Failure{Code: "Unavailable", Message: "model overloaded", Retryable: true}},
+		}},
+		"flaky": {Steps: []mockagent.Step{
+			{Status: "WORKING"},
+			{Disconnect: true},
+		}},
+		"quick": {Steps: []mockagent.Step{
+			{Status: "COMPLETED", Message: "done"},
+		}},
+	}}
+	require.NoError(t, sc.Validate())
+	agent := mockagent.New(sc)
+	card, err := protojson.Marshal(agent.Card())
+	require.NoError(t, err)
+
+	m := meshtest.New(t, meshtest.Config{})
+	m.AddAgent("did:peer:mock", string(card), agent)
+	entry, err := m.Registry().GetAgent(context.Background(), &pb.GetAgentRequest{AgentId: "did:peer:mock"})
+	require.NoError(t, err)
+	assert.Equal(t, "mock-agent", entry.AgentCard.Name)
+	assert.Equal(t, []string{"flaky", "overloaded", "quick", "summarize", "translate"}, skillIDs(entry.AgentCard))
+	caller := m.AddCaller("did:peer:caller")
+	ctx := context.Background()
+	ask := func(skill, text, taskID string) (meshtest.Events, error) {
+		return caller.SendTask(ctx, "did:peer:mock", &mesh.TaskSendRequest{
+			TaskId:        taskID,
+			TargetAgentId: skill,
+			Message:       meshtest.Text(text),
+		})
+	}
+
+	events, err := ask("summarize", "the minutes", "")
+	require.NoError(t, err)
+	events.AssertStatuses(t, mesh.Task_WORKING, mesh.Task_COMPLETED)
+	events.AssertResponse(t, "summary of the minutes")
+	assert.Equal(t, []string{"reading"}, events.Messages())
+
+	// The answer to the question continues the task on a new stream.
+	events, err = ask("translate", "hello", "")
+	require.NoError(t, err)
+	events.AssertStatuses(t, mesh.Task_WORKING, mesh.Task_INPUT_REQUIRED)
+	assert.Equal(t, "Which language?", events.Final().Message)
+	events, err = ask("", "French", events.Final().TaskId)
+	require.NoError(t, err)
+	events.AssertStatuses(t, mesh.Task_WORKING, mesh.Task_COMPLETED)
+	events.AssertResponse(t, "in French")
+
+	events, err = ask("overloaded", "hello", "")
+	require.NoError(t, err)
+	events.AssertStatuses(t, mesh.Task_WORKING, mesh.Task_FAILED)
+	assert.Equal(t, "Unavailable", events.Final().Error.Code)
+	assert.True(t, events.Final().Error.Retryable)
+
+	// A terminal status ends the task without a second final status.
+	events, err = ask("quick", "hello", "")
+	require.NoError(t, err)
+	events.AssertStatuses(t, mesh.Task_COMPLETED)
+	assert.Equal(t, "done", events.Final().Message)
+
+	_, err = ask("flaky", "hello", "")
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	events, err = ask("unknown", "hello", "")
+	require.NoError(t, err)
+	events.AssertStatuses(t, mesh.Task_REJECTED)
+}
+
+func TestMockAgentScenarioFile(t *testing.T) {
+	sc, err := mockagent.LoadScenario("../cmd/mock_agent/scenarios/example.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, 500*time.Millisecond, sc.Skills["summarize"].Steps[1].Delay)
+
+	for _, tc := range []struct {
+		steps []mockagent.Step
+		err   string
+	}{
+		{[]mockagent.Step{{Status: "DONE"}}, `step 1: unknown status "DONE"`},
+		{[]mockagent.Step{{Status: "INPUT_REQUIRED"}}, `step 1: use "input" to ask for input`},
+		{[]mockagent.Step{{Status: "COMPLETED"}, {Status: "WORKING"}}, "step 2: steps follow the end of the task"},
+		{[]mockagent.Step{{Fail: &mockagent.Failure{Code: "Internal"}}, {Input: "again?"}}, "step 2: steps follow the end of the task"},
+		{[]mockagent.Step{{Disconnect: true}, {Delay: time.Second}}, "step 2: steps follow the end of the task"},
+	} {
+		bad := &mockagent.Scenario{Skills: map[string]mockagent.Script{"x": {Steps: tc.steps}}}
+		assert.ErrorContains(t, bad.Validate(), tc.err)
+	}
+}
@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"iter"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	adkagent "google.golang.org/adk/agent"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/adk"
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+)
+
+// mirrorAgent answers with the parts of the user content it received.
+func mirrorAgent(t *testing.T) adkagent.Agent {
+	t.Helper()
+	agent, err := adkagent.New(adkagent.Config{
+		Name:        "mirror_agent",
+		Description: "Returns its input.",
+		Run: func(ctx adkagent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				evt := session.NewEvent(ctx.InvocationID())
+				evt.Author = "mirror_agent"
+				evt.LLMResponse.Content = &genai.Content{Role: genai.RoleModel, Parts: ctx.UserContent().Parts}
+				yield(evt, nil)
+			}
+		},
+	})
+	require.NoError(t, err)
+	return agent
+}
+
+func TestServerWrapperParts(t *testing.T) {
+	client := startMesh(t, "did:peer:mirror", "mirror", adk.NewServerWrapper(mirrorAgent(t)))
+
+	data, err := structpb.NewStruct(map[string]any{"city": "Colombo"})
+	require.NoError(t, err)
+	stream, err := client.StreamTask(metadata.AppendToOutgoingContext(context.Background(), "x-target-skill", "mirror"))
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&mesh.StreamEvent{Event: &mesh.StreamEvent_TaskStart{
+		TaskStart: &mesh.TaskStart{Request: &mesh.TaskSendRequest{Message: &mesh.Message{
+			Role: "user",
+			Parts: []*mesh.Part{
+				{Content: &mesh.Part_TextPart{TextPart: "describe"}},
+				{Content: &mesh.Part_FilePart{FilePart: &mesh.FilePart{
+					Data:     &mesh.FilePart_InlineBytes{InlineBytes: []byte{0x89, 'P', 'N', 'G'}},
+					MimeType: "image/png",
+				}}},
+				{Content: &mesh.Part_FilePart{FilePart: &mesh.FilePart{
+					Data:     &mesh.FilePart_Uri{Uri: "gs://bucket/report.pdf"},
+					MimeType: "application/pdf",
+				}}},
+				{Content: &mesh.Part_DataPart{DataPart: data}},
+			},
+		}}},
+	}}))
+	require.NoError(t, stream.CloseSend())
+
+	var messages []string
+	var artifacts []*mesh.Artifact
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		if msg := event.GetStatusUpdate().GetMessage(); msg != "" {
+			messages = append(messages, msg)
+		}
+		if art := event.GetArtifactUpdate(); art != nil {
+			artifacts = append(artifacts, art.Artifact)
+		}
+	}
+
+	assert.Empty(t, messages)
+	require.Len(t, artifacts, 3)
+	assert.Equal(t, "image/png", artifacts[0].MimeType)
+	assert.Equal(t, []byte{0x89, 'P', 'N', 'G'}, artifacts[0].GetBytes())
+	assert.Equal(t, "application/pdf", artifacts[1].MimeType)
+	assert.Equal(t, "gs://bucket/report.pdf", artifacts[1].GetUri())
+	// The text parts, the data as JSON, make up the final response, one per line.
+	assert.Equal(t, "response", artifacts[2].Name)
+	assert.Equal(t, "text/plain", artifacts[2].MimeType)
+	assert.Equal(t, "describe\n{\"city\":\"Colombo\"}", string(artifacts[2].GetBytes()))
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/adk"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/meshtest"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/sidecar"
+)
+
+// proxyConns counts the TCP connections through a proxy.
+type proxyConns struct {
+	accepted atomic.Int32
+	open     atomic.Int32
+}
+
+// countingProxy forwards TCP connections to target and counts them.
+func countingProxy(t *testing.T, target string) (string, *proxyConns) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { lis.Close() })
+
+	conns := &proxyConns{}
+	go func() {
+		for {
+			in, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			conns.accepted.Add(1)
+			out, err := net.Dial("tcp", target)
+			if err != nil {
+				in.Close()
+				continue
+			}
+			conns.open.Add(1)
+			var wg sync.WaitGroup
+			wg.Add(2)
+			go func() { defer wg.Done(); io.Copy(out, in); out.Close() }()
+			go func() { defer wg.Done(); io.Copy(in, out); in.Close() }()
+			go func() { wg.Wait(); conns.open.Add(-1) }()
+		}
+	}()
+	return lis.Addr().String(), conns
+}
+
+func TestConnectionReuse(t *testing.T) {
+	m := meshtest.New(t, meshtest.Config{})
+	cardFile := filepath.Join(t.TempDir(), "card.json")
+	require.NoError(t, os.WriteFile(cardFile, []byte(`{"name": "history", "protocolVersion": "1.0", "skills": [{"id": "history", "name": "history"}]}`), 0o600))
+
+	// Other sidecars reach the history agent's sidecar through remoteProxy.
+	external := meshtest.Listen(t)
+	remoteProxy, remoteConns := countingProxy(t, external.Addr().String())
+	m.StartSidecar(sidecar.Config{
+		AgentID:          "did:peer:history",
+		ExternalListener: external,
+		AppPort:          meshtest.ServeAgent(t, adk.NewServerWrapper(historyAgent(t))),
+		AgentCardFile:    cardFile,
+		AdvertiseAddr:    remoteProxy,
+	})
+	m.WaitRegistered("did:peer:history")
+
+	// Remote tools reach the caller's sidecar through localProxy, with a pool of their own.
+	caller := m.StartSidecar(sidecar.Config{AgentID: "did:peer:caller", ConnIdleTimeout: 200 * time.Millisecond})
+	localProxy, localConns := countingProxy(t, caller.LocalAddr())
+	pool := adk.NewClientPool()
+	t.Cleanup(func() { pool.Close() })
+
+	history, err := adk.RemoteToolWithConfig(adk.RemoteToolConfig{
+		Name:   "history",
+		Skill:  "history",
+		Client: pool.Client(localProxy),
+	})
+	require.NoError(t, err)
+	for range 3 {
+		resp := callTool(t, history, map[string]any{"request": "count"})
+		assert.Equal(t, "events: 1\n", resp["result"])
+	}
+	assert.Equal(t, int32(1), localConns.accepted.Load(), "tool calls share one connection to the sidecar")
+	assert.Equal(t, int32(1), remoteConns.accepted.Load(), "tasks share one connection to the remote sidecar")
+
+	// Once idle for longer than ConnIdleTimeout, the remote connection is closed.
+	require.Eventually(t, func() bool { return remoteConns.open.Load() == 0 }, 2*time.Second, 10*time.Millisecond)
+	resp := callTool(t, history, map[string]any{"request": "count"})
+	assert.Equal(t, "events: 1\n", resp["result"])
+	assert.Equal(t, int32(2), remoteConns.accepted.Load())
+	assert.Equal(t, int32(1), localConns.accepted.Load())
+
+	// Closing the pool closes the connection to the sidecar; the next call dials again.
+	require.NoError(t, pool.Close())
+	require.Eventually(t, func() bool { return localConns.open.Load() == 0 }, 2*time.Second, 10*time.Millisecond)
+	resp = callTool(t, history, map[string]any{"request": "count"})
+	assert.Equal(t, "events: 1\n", resp["result"])
+	assert.Equal(t, int32(2), localConns.accepted.Load())
+}
@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"context"
+	"iter"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	adkagent "google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/adk"
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+)
+
+func TestRemoteAgent(t *testing.T) {
+	startMesh(t, "did:peer:greeter", "greeting", greeterAgent(t))
+
+	greeter, err := adk.NewRemoteAgent(adk.RemoteAgentConfig{
+		Name:        "remote_greeter",
+		Description: "Greets the user by name.",
+		Skill:       "greeting",
+	})
+	require.NoError(t, err)
+
+	// The root agent hands every turn over to the remote greeter.
+	root, err := llmagent.New(llmagent.Config{
+		Name: "root",
+		Model: callerModel{call: &genai.FunctionCall{
+			Name: "transfer_to_agent",
+			Args: map[string]any{"agent_name": "remote_greeter"},
+		}},
+		SubAgents: []adkagent.Agent{greeter},
+	})
+	require.NoError(t, err)
+
+	sessions := session.InMemoryService()
+	_, err = sessions.Create(context.Background(), &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "s"})
+	require.NoError(t, err)
+	r, err := runner.New(runner.Config{AppName: "app", Agent: root, SessionService: sessions})
+	require.NoError(t, err)
+
+	// progress holds the partial events streamed while the remote task runs.
+	var progress []*session.Event
+	say := func(text string) []string {
+		var replies []string
+		for evt, err := range r.Run(context.Background(), "user", "s", genai.NewContentFromText(text, genai.RoleUser), adkagent.RunConfig{}) {
+			require.NoError(t, err)
+			require.Empty(t, evt.ErrorMessage)
+			if evt.Author != "remote_greeter" {
+				continue
+			}
+			if evt.Partial {
+				progress = append(progress, evt)
+			} else if evt.Content != nil {
+				replies = append(replies, evt.Content.Parts[0].Text)
+			}
+		}
+		return replies
+	}
+
+	// The remote agent's question ends the first turn; the answer continues its task.
+	assert.Equal(t, []string{"What is your name?"}, say("hi"))
+	assert.Equal(t, []string{"Hello, Ada"}, say("Ada"))
+	assert.Equal(t, []string{"What is your name?"}, say("hi again"), "a new task after completion")
+
+	// Each task reported WORKING and the remote tool call as it ran.
+	var statuses []string
+	for _, evt := range progress {
+		statuses = append(statuses, evt.CustomMetadata["agentmesh:status"].(string))
+		assert.NotEmpty(t, evt.CustomMetadata["agentmesh:task_id"])
+	}
+	assert.Equal(t, []string{"WORKING", "WORKING", "WORKING", "WORKING", "WORKING"}, statuses)
+	call, ok := progress[1].CustomMetadata["agentmesh:data"].(map[string]any)
+	require.True(t, ok, "tool event data: %#v", progress[1].CustomMetadata)
+	assert.Equal(t, "request_input", call["name"])
+	assert.Equal(t, "Calling tool request_input", progress[1].Content.Parts[0].Text)
+
+	// Progress is not kept in the session.
+	resp, err := sessions.Get(context.Background(), &session.GetRequest{AppName: "app", UserID: "user", SessionID: "s"})
+	require.NoError(t, err)
+	for evt := range resp.Session.Events().All() {
+		assert.False(t, evt.Partial)
+	}
+}
+
+func TestRemoteAgentFailure(t *testing.T) {
+	startMesh(t, "did:peer:replay", "replay", &replayAgent{updates: []*mesh.TaskStatusUpdate{
+		{TaskId: "task-1", Status: mesh.Task_FAILED, Error: &mesh.TaskError{Code: "Unavailable", Message: "model overloaded", Retryable: true}},
+	}})
+
+	remote, err := adk.NewRemoteAgent(adk.RemoteAgentConfig{Name: "remote", Skill: "replay"})
+	require.NoError(t, err)
+	sessions := session.InMemoryService()
+	_, err = sessions.Create(context.Background(), &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "s"})
+	require.NoError(t, err)
+	r, err := runner.New(runner.Config{AppName: "app", Agent: remote, SessionService: sessions})
+	require.NoError(t, err)
+
+	var events []*session.Event
+	for evt, err := range r.Run(context.Background(), "user", "s", genai.NewContentFromText("hi", genai.RoleUser), adkagent.RunConfig{}) {
+		require.NoError(t, err)
+		events = append(events, evt)
+	}
+	require.Len(t, events, 1)
+	assert.Equal(t, "Unavailable", events[0].ErrorCode)
+	assert.Equal(t, "model overloaded", events[0].ErrorMessage)
+}
+
+// chunkedModel answers "Hello, mesh" in two partial chunks when asked to stream.
+type chunkedModel struct{}
+
+func (chunkedModel) Name() string { return "chunked-model" }
+
+func (chunkedModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		if stream {
+			for _, chunk := range []string{"Hello, ", "mesh"} {
+				if !yield(&model.LLMResponse{Content: genai.NewContentFromText(chunk, genai.RoleModel), Partial: true}, nil) {
+					return
+				}
+			}
+		}
+		yield(&model.LLMResponse{Content: genai.NewContentFromText("Hello, mesh", genai.RoleModel), TurnComplete: true}, nil)
+	}
+}
+
+func TestRemoteAgentStreaming(t *testing.T) {
+	for _, tc := range []struct {
+		mode   adkagent.StreamingMode
+		chunks []string
+	}{
+		{mode: adkagent.StreamingModeSSE, chunks: []string{"Hello, ", "mesh"}},
+		{mode: adkagent.StreamingModeNone},
+	} {
+		t.Run(string(tc.mode), func(t *testing.T) {
+			chunked, err := llmagent.New(llmagent.Config{Name: "chunked", Model: chunkedModel{}})
+			require.NoError(t, err)
+			startMesh(t, "did:peer:chunked", "chunked", adk.NewServerWrapperWithConfig(chunked, adk.ServerConfig{StreamingMode: tc.mode}))
+
+			remote, err := adk.NewRemoteAgent(adk.RemoteAgentConfig{Name: "remote", Skill: "chunked"})
+			require.NoError(t, err)
+			sessions := session.InMemoryService()
+			_, err = sessions.Create(context.Background(), &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "s"})
+			require.NoError(t, err)
+			r, err := runner.New(runner.Config{AppName: "app", Agent: remote, SessionService: sessions})
+			require.NoError(t, err)
+
+			// The remote model's partial chunks cross the mesh as progress, ahead of the answer.
+			var chunks, replies []string
+			for evt, err := range r.Run(context.Background(), "user", "s", genai.NewContentFromText("hi", genai.RoleUser), adkagent.RunConfig{}) {
+				require.NoError(t, err)
+				switch {
+				case evt.Content == nil:
+				case evt.Partial:
+					chunks = append(chunks, evt.Content.Parts[0].Text)
+				default:
+					replies = append(replies, evt.Content.Parts[0].Text)
+				}
+			}
+			assert.Equal(t, tc.chunks, chunks)
+			assert.Equal(t, []string{"Hello, mesh"}, replies)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package tests
+
+import (
+	"iter"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	adkagent "google.golang.org/adk/agent"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/genai"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/adk"
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/meshtest"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/mockagent"
+)
+
+func TestRemoteToolProgress(t *testing.T) {
+	startMesh(t, "did:peer:replay", "replay", &replayAgent{updates: []*mesh.TaskStatusUpdate{
+		{TaskId: "task-1", Status: mesh.Task_WORKING, Message: "step 1"},
+		{TaskId: "task-1", Status: mesh.Task_WORKING, Message: "step 2"},
+		{TaskId: "task-1", Status: mesh.Task_COMPLETED, Message: "done"},
+	}})
+
+	var (
+		mu     sync.Mutex
+		events []*session.Event
+	)
+	remote, err := adk.RemoteToolWithConfig(adk.RemoteToolConfig{
+		Name:  "replay",
+		Skill: "replay",
+		OnProgress: func(ctx tool.Context, evt *session.Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, evt)
+		},
+	})
+	require.NoError(t, err)
+
+	resp := callTool(t, remote, map[string]any{"request": "go"})
+	assert.Equal(t, "step 1\nstep 2\ndone\n", resp["result"])
+
+	mu.Lock()
+	defer mu.Unlock()
+	var statuses, texts []string
+	for _, evt := range events {
+		assert.True(t, evt.Partial)
+		assert.Equal(t, "caller", evt.Author)
+		assert.Equal(t, "replay", evt.CustomMetadata["agentmesh:tool"])
+		assert.Equal(t, "task-1", evt.CustomMetadata["agentmesh:task_id"])
+		assert.NotEmpty(t, evt.CustomMetadata["agentmesh:function_call_id"])
+		statuses = append(statuses, evt.CustomMetadata["agentmesh:status"].(string))
+		texts = append(texts, evt.Content.Parts[0].Text)
+	}
+	assert.Equal(t, []string{"WORKING", "WORKING", "COMPLETED"}, statuses)
+	assert.Equal(t, []string{"step 1", "step 2", "done"}, texts)
+}
+
+func TestRemoteToolResponse(t *testing.T) {
+	// The agent thinks aloud before it answers.
+	agent, err := adkagent.New(adkagent.Config{
+		Name: "thinking_agent",
+		Run: func(ctx adkagent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				thought := session.NewEvent(ctx.InvocationID())
+				thought.Author = "thinking_agent"
+				thought.LLMResponse.Content = &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{{Text: "Let me think.", Thought: true}}}
+				if !yield(thought, nil) {
+					return
+				}
+				answer := session.NewEvent(ctx.InvocationID())
+				answer.Author = "thinking_agent"
+				answer.LLMResponse.Content = genai.NewContentFromText("42", genai.RoleModel)
+				yield(answer, nil)
+			}
+		},
+	})
+	require.NoError(t, err)
+	startMesh(t, "did:peer:thinking", "thinking", adk.NewServerWrapper(agent))
+
+	var (
+		mu       sync.Mutex
+		progress []string
+	)
+	remote, err := adk.RemoteToolWithConfig(adk.RemoteToolConfig{
+		Name:  "thinking",
+		Skill: "thinking",
+		OnProgress: func(ctx tool.Context, evt *session.Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			if evt.Content != nil {
+				progress = append(progress, evt.Content.Parts[0].Text)
+			}
+		},
+	})
+	require.NoError(t, err)
+
+	// The result is the response alone; the thought only reaches OnProgress.
+	resp := callTool(t, remote, map[string]any{"request": "What is the answer?"})
+	assert.Equal(t, map[string]any{"result": "42\n"}, resp)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"Let me think.", "42"}, progress)
+}
+
+func TestRemoteToolData(t *testing.T) {
+	response := &mockagent.Artifact{Name: "response", MimeType: "application/json", Text: `{"rows": 2}`}
+	agent := mockagent.New(&mockagent.Scenario{Skills: map[string]mockagent.Script{
+		"complete": {Steps: []mockagent.Step{{Artifact: response}}},
+		"fail": {Steps: []mockagent.Step{
+			{Artifact: response},
+			{Fail: &mockagent.Failure{Code: "Unavailable", Message: "query interrupted"}},
+		}},
+		"ask": {Steps: []mockagent.Step{
+			{Artifact: response},
+			{Input: "More rows?"},
+		}},
+	}})
+	card, err := protojson.Marshal(agent.Card())
+	require.NoError(t, err)
+	m := meshtest.New(t, meshtest.Config{})
+	m.AddAgent("did:peer:mock", string(card), agent)
+	m.AddCaller("did:peer:caller").UseForRemoteTools()
+
+	// The structured output reaches the calling agent whatever the task's status.
+	for skill, status := range map[string]any{"complete": nil, "fail": "failed", "ask": "input_required"} {
+		remote, err := adk.RemoteTool(skill, "Queries rows.", skill)
+		require.NoError(t, err)
+		resp := callTool(t, remote, map[string]any{"request": "go"})
+		assert.Equal(t, status, resp["status"], skill)
+		assert.Equal(t, map[string]any{"rows": float64(2)}, resp["data"], skill)
+	}
+}
+
+func TestRemoteToolMaxOutput(t *testing.T) {
+	startMesh(t, "did:peer:replay", "replay", &replayAgent{updates: []*mesh.TaskStatusUpdate{
+		{TaskId: "task-1", Status: mesh.Task_WORKING, Message: strings.Repeat("a", 8)},
+		{TaskId: "task-1", Status: mesh.Task_WORKING, Message: strings.Repeat("b", 8)},
+		{TaskId: "task-1", Status: mesh.Task_COMPLETED},
+	}})
+
+	remote, err := adk.RemoteToolWithConfig(adk.RemoteToolConfig{Name: "replay", Skill: "replay", MaxOutputBytes: 12})
+	require.NoError(t, err)
+
+	resp := callTool(t, remote, map[string]any{"request": "go"})
+	assert.Equal(t, "failed", resp["status"])
+	assert.Equal(t, "aaaaaaaa\nbbb", resp["result"])
+	taskErr := resp["error"].(map[string]any)
+	assert.Equal(t, "ResourceExhausted", taskErr["code"])
+	assert.Equal(t, false, taskErr["retryable"])
+}
+
+func TestRemoteToolTimeout(t *testing.T) {
+	agent, stopped := blockingAgent(t)
+	startMesh(t, "did:peer:blocking", "blocking", adk.NewServerWrapper(agent))
+
+	remote, err := adk.RemoteToolWithConfig(adk.RemoteToolConfig{Name: "blocking", Skill: "blocking", Timeout: 100 * time.Millisecond})
+	require.NoError(t, err)
+
+	resp := callTool(t, remote, map[string]any{"request": "go"})
+	assert.Equal(t, "failed", resp["status"])
+	taskErr := resp["error"].(map[string]any)
+	assert.Equal(t, "DeadlineExceeded", taskErr["code"])
+	assert.Equal(t, true, taskErr["retryable"])
+	assert.Contains(t, taskErr["message"], "timed out after 100ms")
+
+	// The remote run is canceled with the call.
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("remote run was not canceled")
+	}
+}
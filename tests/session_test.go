@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	adkagent "google.golang.org/adk/agent"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/adk"
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/meshtest"
+)
+
+// historyAgent answers with the number of events in its session.
+func historyAgent(t *testing.T) adkagent.Agent {
+	t.Helper()
+	agent, err := adkagent.New(adkagent.Config{
+		Name:        "history_agent",
+		Description: "Counts the events in its session.",
+		Run: func(ctx adkagent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				evt := session.NewEvent(ctx.InvocationID())
+				evt.Author = "history_agent"
+				evt.LLMResponse.Content = genai.NewContentFromText(fmt.Sprintf("events: %d", ctx.Session().Events().Len()), genai.RoleModel)
+				yield(evt, nil)
+			}
+		},
+	})
+	require.NoError(t, err)
+	return agent
+}
+
+// askAgent runs one task directly against the agent on port as caller and
+// returns the agent's response.
+func askAgent(t *testing.T, port int, caller, contextID string) []string {
+	t.Helper()
+	conn, err := grpc.NewClient(fmt.Sprintf("127.0.0.1:%d", port), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-caller-id", caller)
+	stream, err := mesh.NewA2AMeshServiceClient(conn).StreamTask(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&mesh.StreamEvent{Event: &mesh.StreamEvent_TaskStart{
+		TaskStart: &mesh.TaskStart{Request: &mesh.TaskSendRequest{ContextId: contextID, Message: userMessage("count")}},
+	}}))
+	require.NoError(t, stream.CloseSend())
+
+	var events []*mesh.StreamEvent
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return response(events)
+		}
+		require.NoError(t, err)
+		events = append(events, event)
+	}
+}
+
+func TestServerWrapperSessions(t *testing.T) {
+	port := meshtest.ServeAgent(t, adk.NewServerWrapper(historyAgent(t)))
+
+	// Each task adds the user message and the agent's answer to the session.
+	assert.Equal(t, []string{"events: 1"}, askAgent(t, port, "alice", "ctx-1"))
+	assert.Equal(t, []string{"events: 3"}, askAgent(t, port, "alice", "ctx-1"))
+	assert.Equal(t, []string{"events: 1"}, askAgent(t, port, "alice", "ctx-2"), "other context")
+	assert.Equal(t, []string{"events: 1"}, askAgent(t, port, "bob", "ctx-1"), "other caller")
+	assert.Equal(t, []string{"events: 1"}, askAgent(t, port, "alice", ""), "no context")
+	assert.Equal(t, []string{"events: 1"}, askAgent(t, port, "alice", ""), "no context")
+}
+
+func TestServerWrapperSessionsPerCaller(t *testing.T) {
+	m := meshtest.New(t, meshtest.Config{})
+	m.AddAgent("did:peer:history", `{"name": "history", "protocolVersion": "1.0"}`, adk.NewServerWrapper(historyAgent(t)))
+	alice, bob := m.AddCaller("did:peer:alice"), m.AddCaller("did:peer:bob")
+
+	// The sidecars tell the callers apart by their certificates, so the same
+	// context ID names a different session for each.
+	ask := func(caller *meshtest.Sidecar) []string {
+		events, err := caller.SendTask(context.Background(), "did:peer:history", &mesh.TaskSendRequest{
+			ContextId: "ctx-1",
+			Message:   meshtest.Text("count"),
+		})
+		require.NoError(t, err)
+		return events.Response()
+	}
+	assert.Equal(t, []string{"events: 1"}, ask(alice))
+	assert.Equal(t, []string{"events: 3"}, ask(alice))
+	assert.Equal(t, []string{"events: 1"}, ask(bob))
+}
+
+func TestServerWrapperSessionExpiry(t *testing.T) {
+	ctx := context.Background()
+	sessions := session.InMemoryService()
+	// A session left over from an earlier run of the agent.
+	_, err := sessions.Create(ctx, &session.CreateRequest{AppName: "history_agent", UserID: "bob", SessionID: "ctx-old"})
+	require.NoError(t, err)
+
+	wrapper := adk.NewServerWrapperWithConfig(historyAgent(t), adk.ServerConfig{
+		SessionService: sessions,
+		SessionTTL:     20 * time.Millisecond,
+	})
+	go wrapper.Run(t.Context())
+	port := meshtest.ServeAgent(t, wrapper)
+
+	assert.Equal(t, []string{"events: 1"}, askAgent(t, port, "alice", "ctx-1"))
+	require.Eventually(t, func() bool {
+		resp, err := sessions.List(ctx, &session.ListRequest{AppName: "history_agent"})
+		require.NoError(t, err)
+		return len(resp.Sessions) == 0
+	}, time.Second, 10*time.Millisecond, "idle sessions expired")
+	assert.Equal(t, []string{"events: 1"}, askAgent(t, port, "alice", "ctx-1"), "expired session starts over")
+}
@@ -0,0 +1,451 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	adkagent "google.golang.org/adk/agent"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	grpcHandler "github.com/ThisaraWeerakoon/Agent-Mesh/internal/adapters/handler/grpc"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/internal/adapters/repository/memory"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/internal/core/domain"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/internal/core/ports"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/internal/core/services"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/adk"
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+	pb "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/registry"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/lifecycle"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/meshtest"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/sidecar"
+)
+
+// startRegistry serves the registry gRPC API on an ephemeral port.
+func startRegistry(t *testing.T) (string, ports.RegistryService) {
+	t.Helper()
+	service := services.NewRegistryService(memory.NewRegistryRepository())
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := grpc.NewServer()
+	pb.RegisterRegistryServiceServer(s, grpcHandler.NewRegistryServer(service))
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	return lis.Addr().String(), service
+}
+
+func TestSidecarSelfRegistration(t *testing.T) {
+	registryAddr, service := startRegistry(t)
+
+	cardFile := filepath.Join(t.TempDir(), "card.json")
+	require.NoError(t, os.WriteFile(cardFile, []byte(`{
+		"name": "summary_agent",
+		"protocolVersion": "1.0",
+		"skills": [{"name": "summarize"}]
+	}`), 0o600))
+
+	m := meshtest.New(t, meshtest.Config{RegistryAddr: registryAddr})
+	s := m.StartSidecar(sidecar.Config{
+		AgentID:           "did:peer:sidecar-test",
+		AgentCardFile:     cardFile,
+		HeartbeatInterval: 20 * time.Millisecond,
+	})
+	agentID := s.Config.AgentID
+
+	// Registered with the external listener advertised as the grpc interface.
+	require.Eventually(t, func() bool {
+		entry, err := service.GetAgent(context.Background(), agentID)
+		return err == nil && entry.LastHeartbeat != nil
+	}, 2*time.Second, 10*time.Millisecond)
+	entry, _ := service.GetAgent(context.Background(), agentID)
+	require.Len(t, entry.AgentCard.SupportedInterfaces, 1)
+	assert.Equal(t, "grpc", entry.AgentCard.SupportedInterfaces[0].ProtocolBinding)
+	assert.Equal(t, fmt.Sprintf("127.0.0.1:%d", s.Config.ExternalPort), entry.AgentCard.SupportedInterfaces[0].URL)
+
+	// Simulate registry data loss: the sidecar must re-register.
+	require.NoError(t, service.DeleteAgent(context.Background(), agentID))
+	require.Eventually(t, func() bool {
+		_, err := service.GetAgent(context.Background(), agentID)
+		return err == nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Graceful shutdown deregisters the agent.
+	require.NoError(t, s.Stop())
+	_, err := service.GetAgent(context.Background(), agentID)
+	assert.EqualError(t, err, "agent not found")
+}
+
+func TestSidecarReregistersExistingAgent(t *testing.T) {
+	registryAddr, service := startRegistry(t)
+	// The entry left behind by a previous run of the sidecar.
+	_, err := service.RegisterAgent(context.Background(), domain.AgentCard{
+		DID:             "did:peer:restarted",
+		Name:            "old_agent",
+		ProtocolVersion: "1.0",
+	}, nil, nil, "")
+	require.NoError(t, err)
+
+	cardFile := filepath.Join(t.TempDir(), "card.json")
+	require.NoError(t, os.WriteFile(cardFile, []byte(`{"name": "new_agent", "protocolVersion": "1.0"}`), 0o600))
+	m := meshtest.New(t, meshtest.Config{RegistryAddr: registryAddr})
+	m.StartSidecar(sidecar.Config{
+		AgentID:           "did:peer:restarted",
+		AgentCardFile:     cardFile,
+		HeartbeatInterval: time.Hour,
+	})
+
+	// The card is updated and heartbeated without waiting for the first interval.
+	require.Eventually(t, func() bool {
+		entry, err := service.GetAgent(context.Background(), "did:peer:restarted")
+		return err == nil && entry.AgentCard.Name == "new_agent" && entry.LastHeartbeat != nil
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestSidecarRetriesAgentCard(t *testing.T) {
+	// The agent serves its card only from the third request on, e.g. while starting.
+	var requests atomic.Int32
+	cardServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			http.Error(w, "starting", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"name": "late_agent", "protocolVersion": "1.0"}`))
+	}))
+	defer cardServer.Close()
+
+	m := meshtest.New(t, meshtest.Config{})
+	m.StartSidecar(sidecar.Config{
+		AgentID:      "did:peer:late",
+		AppPort:      meshtest.ServeAgent(t, &echoAgent{}),
+		AgentCardURL: cardServer.URL,
+	})
+	m.WaitRegistered("did:peer:late")
+	assert.EqualValues(t, 3, requests.Load())
+}
+
+func TestSidecarHealth(t *testing.T) {
+	m := meshtest.New(t, meshtest.Config{})
+	s := m.StartSidecar(sidecar.Config{
+		AgentID:             "did:peer:sidecar-test",
+		AppPort:             meshtest.ServeAgent(t, &echoAgent{}),
+		HealthCheckInterval: 20 * time.Millisecond,
+	})
+
+	conn, err := grpc.NewClient(s.LocalAddr(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	health := healthpb.NewHealthClient(conn)
+	servingStatus := func() healthpb.HealthCheckResponse_ServingStatus {
+		resp, err := health.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		if err != nil {
+			return healthpb.HealthCheckResponse_UNKNOWN
+		}
+		return resp.Status
+	}
+
+	require.Eventually(t, func() bool {
+		return servingStatus() == healthpb.HealthCheckResponse_SERVING
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Draining the sidecar reports NOT_SERVING to watchers before the listeners close.
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	watch, err := health.Watch(watchCtx, &healthpb.HealthCheckRequest{Service: mesh.A2AMeshService_ServiceDesc.ServiceName})
+	require.NoError(t, err)
+	resp, err := watch.Recv()
+	require.NoError(t, err)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- s.Stop() }()
+	resp, err = watch.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.Status)
+	stopWatch()
+	require.NoError(t, <-stopped)
+}
+
+func TestCallerSidecarHealth(t *testing.T) {
+	m := meshtest.New(t, meshtest.Config{})
+	s := m.StartSidecar(sidecar.Config{
+		AgentID:             "did:peer:caller-only",
+		HealthCheckInterval: 20 * time.Millisecond,
+	})
+
+	conn, err := grpc.NewClient(s.LocalAddr(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	health := healthpb.NewHealthClient(conn)
+
+	// A sidecar without a local agent is healthy as long as its certificate is.
+	require.Eventually(t, func() bool {
+		resp, err := health.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		return err == nil && resp.Status == healthpb.HealthCheckResponse_SERVING
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+// echoAgent answers every TaskStart with its text and completes the task.
+type echoAgent struct {
+	mesh.UnimplementedA2AMeshServiceServer
+}
+
+func (a *echoAgent) StreamTask(stream mesh.A2AMeshService_StreamTaskServer) error {
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		start := event.GetTaskStart()
+		if start == nil {
+			continue
+		}
+		text := start.Request.Message.Parts[0].GetTextPart()
+		for _, update := range []*mesh.TaskStatusUpdate{
+			{TaskId: "task-echo", Status: mesh.Task_WORKING, Message: "echo: " + text},
+			{TaskId: "task-echo", Status: mesh.Task_COMPLETED},
+		} {
+			if err := stream.Send(&mesh.StreamEvent{Event: &mesh.StreamEvent_StatusUpdate{StatusUpdate: update}}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (a *echoAgent) GetTask(ctx context.Context, req *mesh.GetTaskRequest) (*mesh.Task, error) {
+	if req.Id != "task-echo" {
+		return nil, status.Error(codes.NotFound, "unknown task")
+	}
+	return &mesh.Task{Id: req.Id, Status: mesh.Task_COMPLETED}, nil
+}
+
+// startMesh connects a caller sidecar to a second sidecar fronting agent, which offers
+// skill, through a fresh registry, and returns a client for the caller's local listener.
+// RemoteTools created afterwards use the caller sidecar too.
+func startMesh(t *testing.T, agentID, skill string, agent mesh.A2AMeshServiceServer) mesh.A2AMeshServiceClient {
+	t.Helper()
+	card := fmt.Sprintf(`{"name": "remote", "protocolVersion": "1.0", "skills": [{"id": %q, "name": %q}]}`, skill, skill)
+	return startMeshOn(t, meshtest.StartRegistry(t), agentID, card, agent)
+}
+
+// startMeshOn is startMesh on an existing registry, registering the agent with card.
+func startMeshOn(t *testing.T, registryAddr, agentID, card string, agent mesh.A2AMeshServiceServer) mesh.A2AMeshServiceClient {
+	t.Helper()
+	m := meshtest.New(t, meshtest.Config{RegistryAddr: registryAddr})
+	m.AddAgent(agentID, card, agent)
+	caller := m.AddCaller("did:peer:caller")
+	caller.UseForRemoteTools()
+	return caller.Client
+}
+
+func TestSidecarSendTask(t *testing.T) {
+	client := startMesh(t, "did:peer:echo", "echo", &echoAgent{})
+
+	ctx := context.Background()
+	task, err := client.SendTask(ctx, &mesh.TaskSendRequest{
+		TargetAgentId: "did:peer:echo",
+		Message: &mesh.Message{
+			Role:  "user",
+			Parts: []*mesh.Part{{Content: &mesh.Part_TextPart{TextPart: "hello"}}},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "task-echo", task.Id)
+	assert.Equal(t, mesh.Task_COMPLETED, task.Status)
+	require.Len(t, task.History, 2)
+	assert.Equal(t, "echo: hello", task.History[1].Parts[0].GetTextPart())
+
+	got, err := client.GetTask(ctx, &mesh.GetTaskRequest{Id: "task-echo"})
+	require.NoError(t, err)
+	assert.Equal(t, mesh.Task_COMPLETED, got.Status)
+
+	_, err = client.GetTask(ctx, &mesh.GetTaskRequest{Id: "task-unknown"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// The caller's sidecar remembers the task for the local agent.
+	list, err := client.ListTasks(ctx, &mesh.ListTasksRequest{Status: mesh.Task_COMPLETED})
+	require.NoError(t, err)
+	require.Len(t, list.Tasks, 1)
+	assert.Equal(t, "task-echo", list.Tasks[0].Id)
+	assert.Len(t, list.Tasks[0].History, 2)
+}
+
+// replayAgent answers every TaskStart with a fixed sequence of status updates.
+type replayAgent struct {
+	mesh.UnimplementedA2AMeshServiceServer
+	updates []*mesh.TaskStatusUpdate
+}
+
+func (a *replayAgent) StreamTask(stream mesh.A2AMeshService_StreamTaskServer) error {
+	if _, err := stream.Recv(); err != nil {
+		return err
+	}
+	for _, update := range a.updates {
+		if err := stream.Send(&mesh.StreamEvent{Event: &mesh.StreamEvent_StatusUpdate{StatusUpdate: update}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestSidecarGetTaskRemoteUnreachable(t *testing.T) {
+	m := meshtest.New(t, meshtest.Config{})
+	remote := m.AddAgent("did:peer:echo", `{"name": "echo", "protocolVersion": "1.0"}`, &echoAgent{})
+	client := m.AddCaller("did:peer:caller").Client
+
+	ctx := context.Background()
+	task, err := client.SendTask(ctx, &mesh.TaskSendRequest{TargetAgentId: "did:peer:echo", Message: userMessage("hello")})
+	require.NoError(t, err)
+
+	// The task is asked of the remote sidecar while it runs...
+	got, err := client.GetTask(ctx, &mesh.GetTaskRequest{Id: task.Id})
+	require.NoError(t, err)
+	assert.Equal(t, mesh.Task_COMPLETED, got.Status)
+
+	// ...and read from the caller's own record once it is gone.
+	require.NoError(t, remote.Stop())
+	got, err = client.GetTask(ctx, &mesh.GetTaskRequest{Id: task.Id})
+	require.NoError(t, err)
+	assert.Equal(t, mesh.Task_COMPLETED, got.Status)
+	require.Len(t, got.History, 2)
+	assert.Equal(t, "echo: hello", got.History[1].Parts[0].GetTextPart())
+}
+
+func TestSidecarDiscoversBySkill(t *testing.T) {
+	registryAddr, service := startRegistry(t)
+	m := meshtest.New(t, meshtest.Config{RegistryAddr: registryAddr})
+	for _, skill := range []string{"weather", "news"} {
+		m.AddAgent("did:peer:"+skill, fmt.Sprintf(`{"name": %q, "protocolVersion": "1.0", "skills": [{"id": %q, "name": %q}]}`, skill, skill, skill),
+			meshtest.NewAgent(func(ctx context.Context, req *mesh.TaskSendRequest) (string, error) {
+				return skill + " answer", nil
+			}))
+	}
+
+	// The caller offers the skill too and is registered last, so it is listed first.
+	_, err := service.RegisterAgent(context.Background(), domain.AgentCard{
+		DID:                 "did:peer:caller",
+		Name:                "caller",
+		ProtocolVersion:     "1.0",
+		SupportedInterfaces: []domain.AgentInterface{{ProtocolBinding: "grpc", URL: "127.0.0.1:1"}},
+		Skills:              []domain.AgentSkill{{ID: "weather", Name: "weather"}},
+	}, nil, nil, "")
+	require.NoError(t, err)
+	m.AddCaller("did:peer:caller").UseForRemoteTools()
+
+	weather, err := adk.RemoteTool("weather", "Current weather.", "weather")
+	require.NoError(t, err)
+	resp := callTool(t, weather, map[string]any{"request": "Colombo"})
+	assert.Equal(t, map[string]any{"result": "weather answer\n"}, resp)
+
+	sports, err := adk.RemoteTool("sports", "Scores.", "sports")
+	require.NoError(t, err)
+	resp = callTool(t, sports, map[string]any{"request": "Scores"})
+	assert.Equal(t, "failed", resp["status"])
+	assert.Contains(t, resp["error"].(map[string]any)["message"], "no agent found for skill: sports")
+}
+
+func TestSidecarRejectsIllegalTransition(t *testing.T) {
+	client := startMesh(t, "did:peer:replay", "replay", &replayAgent{updates: []*mesh.TaskStatusUpdate{
+		{TaskId: "task-replay", Status: mesh.Task_COMPLETED},
+		{TaskId: "task-replay", Status: mesh.Task_WORKING},
+	}})
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-target-skill", "replay")
+	stream, err := client.StreamTask(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&mesh.StreamEvent{Event: &mesh.StreamEvent_TaskStart{
+		TaskStart: &mesh.TaskStart{Request: &mesh.TaskSendRequest{}},
+	}}))
+
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, mesh.Task_COMPLETED, event.GetStatusUpdate().Status)
+
+	_, err = stream.Recv()
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "invalid transition from COMPLETED to WORKING")
+}
+
+func TestSidecarReusesFinishedTaskID(t *testing.T) {
+	// echoAgent names every task task-echo.
+	client := startMesh(t, "did:peer:echo", "echo", &echoAgent{})
+
+	ctx := context.Background()
+	for _, text := range []string{"first", "second"} {
+		task, err := client.SendTask(ctx, &mesh.TaskSendRequest{
+			TargetAgentId: "did:peer:echo",
+			Message: &mesh.Message{
+				Role:  "user",
+				Parts: []*mesh.Part{{Content: &mesh.Part_TextPart{TextPart: text}}},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, mesh.Task_COMPLETED, task.Status)
+		assert.Equal(t, "echo: "+text, task.History[len(task.History)-1].Parts[0].GetTextPart())
+	}
+
+	got, err := client.GetTask(ctx, &mesh.GetTaskRequest{Id: "task-echo"})
+	require.NoError(t, err)
+	require.Len(t, got.History, 2)
+	assert.Equal(t, "second", got.History[0].Parts[0].GetTextPart())
+}
+
+func TestValidateTransition(t *testing.T) {
+	assert.NoError(t, lifecycle.ValidateTransition(mesh.Task_SUBMITTED, mesh.Task_WORKING))
+	assert.NoError(t, lifecycle.ValidateTransition(mesh.Task_WORKING, mesh.Task_WORKING))
+	assert.NoError(t, lifecycle.ValidateTransition(mesh.Task_INPUT_REQUIRED, mesh.Task_WORKING))
+	assert.EqualError(t, lifecycle.ValidateTransition(mesh.Task_COMPLETED, mesh.Task_WORKING),
+		"invalid transition from COMPLETED to WORKING: task is already COMPLETED")
+	assert.EqualError(t, lifecycle.ValidateTransition(mesh.Task_WORKING, mesh.Task_REJECTED),
+		"invalid transition from WORKING to REJECTED")
+}
+
+func TestServerWrapperReportsFailure(t *testing.T) {
+	agent, err := adkagent.New(adkagent.Config{
+		Name:        "overloaded_agent",
+		Description: "Always fails.",
+		Run: func(ctx adkagent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				yield(nil, genai.APIError{Code: 503, Message: "model overloaded"})
+			}
+		},
+	})
+	require.NoError(t, err)
+	client := startMesh(t, "did:peer:overloaded", "overloaded", adk.NewServerWrapper(agent))
+
+	task, err := client.SendTask(context.Background(), &mesh.TaskSendRequest{
+		TargetAgentId: "did:peer:overloaded",
+		Message:       userMessage("hello"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, mesh.Task_FAILED, task.Status)
+	require.NotNil(t, task.Error)
+	assert.Equal(t, "Unavailable", task.Error.Code)
+	assert.True(t, task.Error.Retryable)
+	assert.Contains(t, task.Error.Message, "model overloaded")
+
+	stored, err := client.GetTask(context.Background(), &mesh.GetTaskRequest{Id: task.Id})
+	require.NoError(t, err)
+	assert.Equal(t, "Unavailable", stored.GetError().GetCode())
+}
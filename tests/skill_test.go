@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	adkagent "google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/genai"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/adk"
+	pb "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/registry"
+)
+
+// weatherAgent answers a JSON {"city": ...} request with a JSON weather report.
+// It does not know the temperature in Atlantis, nor anything about Lemuria.
+func weatherAgent(t *testing.T) adkagent.Agent {
+	t.Helper()
+	agent, err := adkagent.New(adkagent.Config{
+		Name:        "weather_agent",
+		Description: "Reports the weather.",
+		Run: func(ctx adkagent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				var req struct {
+					City string `json:"city"`
+				}
+				if err := json.Unmarshal([]byte(ctx.UserContent().Parts[0].Text), &req); err != nil {
+					yield(nil, err)
+					return
+				}
+				report := fmt.Sprintf(`{"city": %q, "temperature": 31}`, req.City)
+				switch req.City {
+				case "Atlantis":
+					report = `{"city": "Atlantis"}`
+				case "Lemuria":
+					report = "Never heard of it."
+				}
+				evt := session.NewEvent(ctx.InvocationID())
+				evt.Author = "weather_agent"
+				evt.LLMResponse.Content = genai.NewContentFromText(report, genai.RoleModel)
+				yield(evt, nil)
+			}
+		},
+	})
+	require.NoError(t, err)
+	return agent
+}
+
+// callerModel calls a tool once and then ends the turn.
+type callerModel struct {
+	call *genai.FunctionCall
+}
+
+func (m callerModel) Name() string { return "caller-model" }
+
+func (m callerModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		for _, part := range req.Contents[len(req.Contents)-1].Parts {
+			if part.FunctionResponse != nil {
+				yield(&model.LLMResponse{Content: genai.NewContentFromText("done", genai.RoleModel)}, nil)
+				return
+			}
+		}
+		yield(&model.LLMResponse{Content: &genai.Content{
+			Role:  genai.RoleModel,
+			Parts: []*genai.Part{{FunctionCall: m.call}},
+		}}, nil)
+	}
+}
+
+// callTool has an agent call t with args and returns the tool's response.
+func callTool(t *testing.T, tl tool.Tool, args map[string]any) map[string]any {
+	t.Helper()
+	agent, err := llmagent.New(llmagent.Config{
+		Name:  "caller",
+		Model: callerModel{call: &genai.FunctionCall{Name: tl.Name(), Args: args}},
+		Tools: []tool.Tool{tl},
+	})
+	require.NoError(t, err)
+
+	sessions := session.InMemoryService()
+	_, err = sessions.Create(context.Background(), &session.CreateRequest{AppName: "caller", UserID: "user", SessionID: "s"})
+	require.NoError(t, err)
+	r, err := runner.New(runner.Config{AppName: "caller", Agent: agent, SessionService: sessions})
+	require.NoError(t, err)
+
+	for evt, err := range r.Run(context.Background(), "user", "s", genai.NewContentFromText("go", genai.RoleUser), adkagent.RunConfig{}) {
+		require.NoError(t, err)
+		for _, part := range evt.LLMResponse.Content.Parts {
+			if part.FunctionResponse != nil {
+				return part.FunctionResponse.Response
+			}
+		}
+	}
+	t.Fatal("tool was not called")
+	return nil
+}
+
+func TestSkillTool(t *testing.T) {
+	startMesh(t, "did:peer:weather", "weather", adk.NewServerWrapperWithConfig(weatherAgent(t), adk.ServerConfig{
+		OutputSchema: &genai.Schema{Type: genai.TypeObject},
+	}))
+
+	params, err := structpb.NewStruct(map[string]any{
+		"skills": map[string]any{
+			"weather": map[string]any{
+				"inputSchema": map[string]any{
+					"type":       "object",
+					"properties": map[string]any{"city": map[string]any{"type": "string"}},
+					"required":   []any{"city"},
+				},
+				"outputSchema": map[string]any{
+					"type":     "object",
+					"required": []any{"city", "temperature"},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	card := &pb.AgentCard{
+		Name: "weather",
+		Capabilities: &pb.AgentCapabilities{Extensions: []*pb.AgentExtension{
+			{Uri: adk.SkillSchemaExtension, Params: params},
+		}},
+	}
+	skill := &pb.AgentSkill{Id: "weather", Name: "Weather", Description: "Current weather for a city."}
+
+	weather, err := adk.SkillTool(card, skill)
+	require.NoError(t, err)
+	assert.Equal(t, "weather", weather.Name())
+
+	resp := callTool(t, weather, map[string]any{"city": "Colombo"})
+	assert.Equal(t, map[string]any{"city": "Colombo", "temperature": float64(31)}, resp)
+
+	resp = callTool(t, weather, map[string]any{"city": "Atlantis"})
+	assert.Equal(t, "failed", resp["status"])
+	taskErr, ok := resp["error"].(map[string]any)
+	require.True(t, ok, "structured error: %#v", resp)
+	assert.Equal(t, "Internal", taskErr["code"])
+	assert.Equal(t, false, taskErr["retryable"])
+	assert.Contains(t, taskErr["message"], "temperature")
+
+	resp = callTool(t, weather, map[string]any{"city": "Lemuria"})
+	assert.Equal(t, "failed", resp["status"])
+	taskErr, ok = resp["error"].(map[string]any)
+	require.True(t, ok, "structured error: %#v", resp)
+	assert.Contains(t, taskErr["message"], "the response is not a JSON object")
+
+	// Without a declared schema the skill falls back to a request string.
+	plain, err := adk.SkillTool(&pb.AgentCard{Name: "weather"}, skill)
+	require.NoError(t, err)
+	resp = callTool(t, plain, map[string]any{"request": `{"city": "Kandy"}`})
+	assert.Contains(t, resp["result"], `"temperature": 31`)
+}
@@ -0,0 +1,214 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/sidecar"
+)
+
+func TestFileTaskStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "tasks.json")
+
+	store, err := sidecar.NewFileTaskStore(path)
+	require.NoError(t, err)
+
+	key := func(id string) sidecar.TaskKey {
+		return sidecar.TaskKey{Direction: sidecar.Outbound, Peer: "remote:50053", ID: id}
+	}
+	for _, id := range []string{"task-1", "task-2"} {
+		_, err := store.Update(ctx, key(id), func(r *sidecar.TaskRecord) {
+			r.Task.SessionId = "ctx-1"
+			r.Task.Status = mesh.Task_WORKING
+		})
+		require.NoError(t, err)
+	}
+	_, err = store.Update(ctx, key("task-2"), func(r *sidecar.TaskRecord) {
+		r.Task.Status = mesh.Task_COMPLETED
+	})
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	// Each change is appended to the log rather than rewriting the file.
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, bytes.Count(data, []byte("\n")))
+
+	// Reopening the file restores the tasks.
+	store, err = sidecar.NewFileTaskStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	record, err := store.Get(ctx, key("task-2"))
+	require.NoError(t, err)
+	assert.Equal(t, mesh.Task_COMPLETED, record.Task.Status)
+	assert.Equal(t, sidecar.Outbound, record.Direction)
+	assert.Equal(t, "remote:50053", record.Peer)
+
+	records, err := store.List(ctx, sidecar.TaskQuery{ContextID: "ctx-1", Status: mesh.Task_WORKING})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "task-1", records[0].Task.Id)
+
+	// Opening compacts the log to one line per task.
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, bytes.Count(data, []byte("\n")))
+
+	// Expiry is persisted too.
+	removed, err := store.Expire(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	require.NoError(t, store.Close())
+
+	store, err = sidecar.NewFileTaskStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+	_, err = store.Get(ctx, key("task-1"))
+	assert.ErrorIs(t, err, sidecar.ErrTaskNotFound)
+}
+
+func TestFileTaskStoreTornWrite(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	key := sidecar.TaskKey{Direction: sidecar.Inbound, Peer: "sidecar-a", ID: "task-1"}
+
+	store, err := sidecar.NewFileTaskStore(path)
+	require.NoError(t, err)
+	_, err = store.Update(ctx, key, func(r *sidecar.TaskRecord) { r.Task.Status = mesh.Task_WORKING })
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	// A crash cut off the last line.
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"task": {"id": "task-1", "status": "COMP`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	store, err = sidecar.NewFileTaskStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+	record, err := store.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, mesh.Task_WORKING, record.Task.Status)
+}
+
+func TestFileTaskStoreAppendsChanges(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	key := sidecar.TaskKey{Direction: sidecar.Inbound, Peer: "sidecar-a", ID: "task-1"}
+	say := func(text string) func(*sidecar.TaskRecord) {
+		return func(r *sidecar.TaskRecord) {
+			r.Task.Status = mesh.Task_WORKING
+			r.Task.History = append(r.Task.History, &mesh.Message{Role: "agent", Parts: []*mesh.Part{{Content: &mesh.Part_TextPart{TextPart: text}}}})
+		}
+	}
+
+	store, err := sidecar.NewFileTaskStore(path)
+	require.NoError(t, err)
+	for i := range 50 {
+		_, err := store.Update(ctx, key, say(fmt.Sprintf("message %d", i)))
+		require.NoError(t, err)
+	}
+	_, err = store.Update(ctx, key, func(r *sidecar.TaskRecord) { r.Task.Status = mesh.Task_COMPLETED })
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	// Each line holds what its change added, not the whole history again.
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 1, bytes.Count(data, []byte("message 0")))
+
+	store, err = sidecar.NewFileTaskStore(path)
+	require.NoError(t, err)
+	record, err := store.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, mesh.Task_COMPLETED, record.Task.Status)
+	require.Len(t, record.Task.History, 50)
+	assert.Equal(t, "message 49", record.Task.History[49].Parts[0].GetTextPart())
+
+	// A change rewriting the history is logged whole.
+	_, err = store.Update(ctx, key, func(r *sidecar.TaskRecord) {
+		r.Task.History = r.Task.History[:1]
+		say("again")(r)
+	})
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	store, err = sidecar.NewFileTaskStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+	record, err = store.Get(ctx, key)
+	require.NoError(t, err)
+	require.Len(t, record.Task.History, 2)
+	assert.Equal(t, "again", record.Task.History[1].Parts[0].GetTextPart())
+}
+
+func TestFileTaskStoreCorruptLine(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	key := func(id string) sidecar.TaskKey {
+		return sidecar.TaskKey{Direction: sidecar.Inbound, Peer: "sidecar-a", ID: id}
+	}
+	// line returns the log line recording a task with status.
+	line := func(id string, status mesh.Task_Status) []byte {
+		path := filepath.Join(dir, id+".json")
+		store, err := sidecar.NewFileTaskStore(path)
+		require.NoError(t, err)
+		_, err = store.Update(ctx, key(id), func(r *sidecar.TaskRecord) { r.Task.Status = status })
+		require.NoError(t, err)
+		require.NoError(t, store.Close())
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return data
+	}
+
+	// A line in the middle of the log is corrupt.
+	path := filepath.Join(dir, "tasks.json")
+	data := slices.Concat(line("task-1", mesh.Task_WORKING), []byte("{not json\n"), line("task-2", mesh.Task_COMPLETED))
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	// It is skipped; the lines around it are loaded.
+	store, err := sidecar.NewFileTaskStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+	for id, status := range map[string]mesh.Task_Status{"task-1": mesh.Task_WORKING, "task-2": mesh.Task_COMPLETED} {
+		record, err := store.Get(ctx, key(id))
+		require.NoError(t, err)
+		assert.Equal(t, status, record.Task.Status)
+	}
+}
+
+func TestTaskStoreKeysByPeer(t *testing.T) {
+	ctx := context.Background()
+	store := sidecar.NewMemoryTaskStore()
+
+	// Two callers picked the same task ID.
+	a := sidecar.TaskKey{Direction: sidecar.Inbound, Peer: "sidecar-a", ID: "task-1"}
+	b := sidecar.TaskKey{Direction: sidecar.Inbound, Peer: "sidecar-b", ID: "task-1"}
+	_, err := store.Update(ctx, a, func(r *sidecar.TaskRecord) { r.Task.Status = mesh.Task_COMPLETED })
+	require.NoError(t, err)
+	_, err = store.Update(ctx, b, func(r *sidecar.TaskRecord) { r.Task.Status = mesh.Task_WORKING })
+	require.NoError(t, err)
+
+	record, err := store.Get(ctx, a)
+	require.NoError(t, err)
+	assert.Equal(t, mesh.Task_COMPLETED, record.Task.Status)
+	assert.Equal(t, a, record.Key())
+
+	records, err := store.List(ctx, sidecar.TaskQuery{TaskID: "task-1"})
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+}
@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/adk"
+	mesh "github.com/ThisaraWeerakoon/Agent-Mesh/pkg/api/v1/mesh"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/telemetry"
+)
+
+type tracedAgent struct {
+	mesh.UnimplementedA2AMeshServiceServer
+}
+
+func (a *tracedAgent) StreamTask(stream mesh.A2AMeshService_StreamTaskServer) error {
+	_, span := telemetry.Tracer().Start(stream.Context(), "agent.handle")
+	defer span.End()
+
+	for {
+		if _, err := stream.Recv(); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func TestTraceparentPropagatesOverGRPC(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	shutdown, err := telemetry.Setup(context.Background(), telemetry.Config{
+		ServiceName:  "test",
+		SpanExporter: exporter,
+		Sync:         true,
+	})
+	require.NoError(t, err)
+	defer shutdown(context.Background())
+
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer(telemetry.ServerOption())
+	mesh.RegisterA2AMeshServiceServer(srv, &tracedAgent{})
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		telemetry.DialOption(),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, root := telemetry.Tracer().Start(context.Background(), "caller")
+	stream, err := mesh.NewA2AMeshServiceClient(conn).StreamTask(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&mesh.StreamEvent{}))
+	require.NoError(t, stream.CloseSend())
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+	root.End()
+
+	var handled tracetest.SpanStub
+	require.Eventually(t, func() bool {
+		for _, s := range exporter.GetSpans() {
+			if s.Name == "agent.handle" {
+				handled = s
+				return true
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, root.SpanContext().TraceID(), handled.SpanContext.TraceID())
+	assert.True(t, handled.Parent.IsValid())
+}
+
+func TestRemoteToolTrace(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	shutdown, err := telemetry.Setup(context.Background(), telemetry.Config{
+		ServiceName:  "test",
+		SpanExporter: exporter,
+		Sync:         true,
+	})
+	require.NoError(t, err)
+	defer shutdown(context.Background())
+
+	startMesh(t, "did:peer:weather", "weather", adk.NewServerWrapper(weatherAgent(t)))
+	weather, err := adk.RemoteTool("weather", "Current weather.", "weather")
+	require.NoError(t, err)
+	resp := callTool(t, weather, map[string]any{"request": `{"city": "Colombo"}`})
+	require.NotContains(t, resp, "error")
+
+	var spans map[string]tracetest.SpanStub
+	var byID map[trace.SpanID]tracetest.SpanStub
+	// descends reports whether the span named child has the span named parent among
+	// its ancestors. The gRPC client and server spans sit between them.
+	descends := func(child, parent string) bool {
+		s, ok := spans[child]
+		for ok {
+			if s.Name == parent {
+				return true
+			}
+			s, ok = byID[s.Parent.SpanID()]
+		}
+		return false
+	}
+
+	// The gRPC client spans may end after the tool has returned.
+	assert.Eventually(t, func() bool {
+		spans = make(map[string]tracetest.SpanStub)
+		byID = make(map[trace.SpanID]tracetest.SpanStub)
+		for _, s := range exporter.GetSpans() {
+			spans[s.Name] = s
+			byID[s.SpanContext.SpanID()] = s
+		}
+		return descends("sidecar.outbound", "RemoteTool weather") &&
+			descends("sidecar.inbound", "sidecar.outbound") &&
+			descends("adk.run", "sidecar.inbound")
+	}, time.Second, 10*time.Millisecond)
+	for _, name := range []string{"sidecar.outbound", "sidecar.inbound", "adk.run"} {
+		assert.Equal(t, spans["RemoteTool weather"].SpanContext.TraceID(), spans[name].SpanContext.TraceID(), name)
+	}
+}
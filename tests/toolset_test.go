@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	adkagent "google.golang.org/adk/agent"
+	"google.golang.org/adk/tool"
+
+	"github.com/ThisaraWeerakoon/Agent-Mesh/internal/core/domain"
+	"github.com/ThisaraWeerakoon/Agent-Mesh/pkg/adk"
+)
+
+// readonlyContext is the context an agent lists its tools in; only its
+// context.Context methods are used.
+type readonlyContext struct {
+	adkagent.ReadonlyContext
+}
+
+func (readonlyContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (readonlyContext) Done() <-chan struct{}       { return nil }
+func (readonlyContext) Err() error                  { return nil }
+func (readonlyContext) Value(key any) any           { return nil }
+
+func toolNames(t *testing.T, ts *adk.RegistryToolset) []string {
+	t.Helper()
+	tools, err := ts.Tools(readonlyContext{})
+	require.NoError(t, err)
+	var names []string
+	for _, tl := range tools {
+		names = append(names, tl.Name())
+	}
+	return names
+}
+
+func TestRegistryToolset(t *testing.T) {
+	registryAddr, service := startRegistry(t)
+	startMeshOn(t, registryAddr, "did:peer:weather", `{
+		"name": "weather",
+		"protocolVersion": "1.0",
+		"skills": [{"id": "weather", "name": "Weather", "description": "Current weather for a city."}]
+	}`, adk.NewServerWrapper(weatherAgent(t)))
+
+	// The forecast agent is registered last, so a lookup by skill alone would pick it.
+	_, err := service.RegisterAgent(context.Background(), domain.AgentCard{
+		DID:                 "did:peer:forecast",
+		Name:                "forecast",
+		ProtocolVersion:     "1.0",
+		SupportedInterfaces: []domain.AgentInterface{{ProtocolBinding: "grpc", URL: "127.0.0.1:1"}},
+		Skills: []domain.AgentSkill{
+			{ID: "forecast", Name: "Forecast", Description: "Weather forecast."},
+		},
+	}, []string{"beta"}, map[string]interface{}{"namespace": "ops"}, "")
+	require.NoError(t, err)
+
+	ts, err := adk.NewRegistryToolset(adk.RegistryToolsetConfig{RegistryAddr: registryAddr, RefreshInterval: 20 * time.Millisecond})
+	require.NoError(t, err)
+	t.Cleanup(func() { ts.Close() })
+	assert.Equal(t, "agentmesh", ts.Name())
+	assert.ElementsMatch(t, []string{"forecast", "weather"}, toolNames(t, ts))
+
+	for _, cfg := range []adk.RegistryToolsetConfig{
+		{Tags: []string{"beta"}},
+		{Namespace: "ops"},
+		{Skill: "Forecast"},
+	} {
+		cfg.RegistryAddr = registryAddr
+		filtered, err := adk.NewRegistryToolset(cfg)
+		require.NoError(t, err)
+		defer filtered.Close()
+		assert.Contains(t, toolNames(t, filtered), "forecast", "%+v", cfg)
+		assert.NotContains(t, toolNames(t, filtered), "weather", "%+v", cfg)
+	}
+
+	// Tools call the agent they were discovered on.
+	tools, err := ts.Tools(readonlyContext{})
+	require.NoError(t, err)
+	i := slices.IndexFunc(tools, func(tl tool.Tool) bool { return tl.Name() == "weather" })
+	require.NotEqual(t, -1, i)
+	assert.Equal(t, "Current weather for a city.", tools[i].Description())
+	resp := callTool(t, tools[i], map[string]any{"request": `{"city": "Colombo"}`})
+	assert.Contains(t, resp["result"], `"temperature": 31`)
+
+	// Agents leaving the registry drop out on the next refresh.
+	require.NoError(t, service.DeleteAgent(context.Background(), "did:peer:forecast"))
+	assert.Eventually(t, func() bool {
+		names := toolNames(t, ts)
+		return len(names) == 1 && names[0] == "weather"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// A second agent offering the weather skill takes its name, as the most recently
+	// updated one; the first one's tool is qualified with its agent name.
+	_, err = service.RegisterAgent(context.Background(), domain.AgentCard{
+		DID:                 "did:peer:marine",
+		Name:                "marine",
+		ProtocolVersion:     "1.0",
+		SupportedInterfaces: []domain.AgentInterface{{ProtocolBinding: "grpc", URL: "127.0.0.1:1"}},
+		Skills:              []domain.AgentSkill{{ID: "weather", Name: "Marine weather"}},
+	}, nil, nil, "")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"weather", "weather.weather"}, toolNames(t, ts))
+	}, 2*time.Second, 10*time.Millisecond)
+
+	tools, err = ts.Tools(readonlyContext{})
+	require.NoError(t, err)
+	resp = callTool(t, tools[1], map[string]any{"request": `{"city": "Colombo"}`})
+	assert.Contains(t, resp["result"], `"temperature": 31`)
+}